@@ -0,0 +1,142 @@
+// Package apperrors provides a single RFC 7807 ("Problem Details for HTTP
+// APIs") error model shared by every HTTP handler in this service, so
+// clients get one consistent, machine-readable error contract instead of
+// each handler inventing its own ad-hoc JSON shape.
+package apperrors
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// FieldError describes a single field-level validation failure.
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// Problem is the application/problem+json response body, per RFC 7807, with
+// a stable machine-readable Code and the per-request TraceID appended.
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     string       `json:"code"`
+	TraceID  string       `json:"traceId,omitempty"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// Error is an application error that knows how to render itself as a
+// Problem. Handlers construct one of these via the constructors below and
+// hand it to Write.
+type Error struct {
+	Status int
+	Code   string
+	Title  string
+	Detail string
+	Errors []FieldError
+}
+
+func (e *Error) Error() string {
+	if e.Detail != "" {
+		return e.Detail
+	}
+	return e.Title
+}
+
+// NotFound builds a 404 Problem with the given machine-readable code.
+func NotFound(code, detail string) *Error {
+	return &Error{Status: http.StatusNotFound, Code: code, Title: "Not Found", Detail: detail}
+}
+
+// Conflict builds a 409 Problem with the given machine-readable code.
+func Conflict(code, detail string) *Error {
+	return &Error{Status: http.StatusConflict, Code: code, Title: "Conflict", Detail: detail}
+}
+
+// PreconditionFailed builds a 412 Problem, used for If-Match mismatches.
+func PreconditionFailed(code, detail string) *Error {
+	return &Error{Status: http.StatusPreconditionFailed, Code: code, Title: "Precondition Failed", Detail: detail}
+}
+
+// PreconditionRequired builds a 428 Problem, used when a mutating request
+// omits the If-Match header altogether.
+func PreconditionRequired(code, detail string) *Error {
+	return &Error{Status: http.StatusPreconditionRequired, Code: code, Title: "Precondition Required", Detail: detail}
+}
+
+// Validation builds a 422 Problem carrying one entry per field violation.
+func Validation(code string, errs []FieldError) *Error {
+	return &Error{
+		Status: http.StatusUnprocessableEntity,
+		Code:   code,
+		Title:  "Validation Failed",
+		Detail: "one or more fields failed validation",
+		Errors: errs,
+	}
+}
+
+// Internal builds a 500 Problem. The underlying err is never echoed to the
+// client, only logged by the caller, since it may leak internal detail.
+func Internal(code string) *Error {
+	return &Error{Status: http.StatusInternalServerError, Code: code, Title: "Internal Server Error"}
+}
+
+// BadRequest builds a 400 Problem, used for malformed request bodies.
+func BadRequest(code, detail string) *Error {
+	return &Error{Status: http.StatusBadRequest, Code: code, Title: "Bad Request", Detail: detail}
+}
+
+// NotAcceptable builds a 406 Problem, used when none of the client's Accept
+// media types can be produced.
+func NotAcceptable(code, detail string) *Error {
+	return &Error{Status: http.StatusNotAcceptable, Code: code, Title: "Not Acceptable", Detail: detail}
+}
+
+// UnsupportedMediaType builds a 415 Problem, used when a request body's
+// Content-Type cannot be decoded.
+func UnsupportedMediaType(code, detail string) *Error {
+	return &Error{Status: http.StatusUnsupportedMediaType, Code: code, Title: "Unsupported Media Type", Detail: detail}
+}
+
+// RequestEntityTooLarge builds a 413 Problem, used when a request exceeds a
+// handler-enforced size limit (e.g. too many batch operations).
+func RequestEntityTooLarge(code, detail string) *Error {
+	return &Error{Status: http.StatusRequestEntityTooLarge, Code: code, Title: "Request Entity Too Large", Detail: detail}
+}
+
+// Unauthorized builds a 401 Problem, used when a request carries no (or an
+// invalid) bearer token.
+func Unauthorized(code, detail string) *Error {
+	return &Error{Status: http.StatusUnauthorized, Code: code, Title: "Unauthorized", Detail: detail}
+}
+
+// Forbidden builds a 403 Problem, used when an authenticated caller
+// attempts to act on a resource owned by someone else.
+func Forbidden(code, detail string) *Error {
+	return &Error{Status: http.StatusForbidden, Code: code, Title: "Forbidden", Detail: detail}
+}
+
+// Write renders err as an application/problem+json response, echoing the
+// request's trace ID (see middleware.go) into the body.
+func Write(w http.ResponseWriter, r *http.Request, err *Error) {
+	problem := Problem{
+		Type:     "about:blank",
+		Title:    err.Title,
+		Status:   err.Status,
+		Detail:   err.Detail,
+		Instance: r.URL.Path,
+		Code:     err.Code,
+		TraceID:  TraceID(r.Context()),
+		Errors:   err.Errors,
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	if problem.TraceID != "" {
+		w.Header().Set("X-Trace-Id", problem.TraceID)
+	}
+	w.WriteHeader(err.Status)
+	_ = json.NewEncoder(w).Encode(problem)
+}
@@ -0,0 +1,63 @@
+package apperrors
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteRoundTrip(t *testing.T) {
+	t.Run("not found", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/items/99999", nil)
+		req = req.WithContext(req.Context())
+		rr := httptest.NewRecorder()
+
+		Write(rr, req, NotFound("item.not_found", "no item with the given id exists"))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"))
+
+		var problem Problem
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&problem))
+		assert.Equal(t, http.StatusNotFound, problem.Status)
+		assert.Equal(t, "item.not_found", problem.Code)
+		assert.Equal(t, "/items/99999", problem.Instance)
+		assert.Empty(t, problem.Errors)
+	})
+
+	t.Run("validation failure lists every violation", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/items", nil)
+		rr := httptest.NewRecorder()
+
+		Write(rr, req, Validation("item.validation_failed", []FieldError{
+			{Field: "name", Reason: "must not be empty"},
+			{Field: "priority", Reason: "must be a positive integer"},
+		}))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+		var problem Problem
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&problem))
+		require.Len(t, problem.Errors, 2)
+		assert.Equal(t, "name", problem.Errors[0].Field)
+		assert.Equal(t, "priority", problem.Errors[1].Field)
+	})
+
+	t.Run("trace id is echoed from context and header", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/items/1", nil)
+		rr := httptest.NewRecorder()
+
+		TraceIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Write(w, r, NotFound("item.not_found", "missing"))
+		})).ServeHTTP(rr, req)
+
+		var problem Problem
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&problem))
+		require.NotEmpty(t, problem.TraceID)
+		assert.Equal(t, problem.TraceID, rr.Header().Get("X-Trace-Id"))
+	})
+}
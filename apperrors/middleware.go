@@ -0,0 +1,45 @@
+package apperrors
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+type traceIDKey struct{}
+
+// TraceIDMiddleware assigns every request a trace ID (reusing an inbound
+// X-Trace-Id/X-Request-Id header when present), stores it in the request
+// context, and echoes it back on the response so Write can include it in
+// Problem bodies.
+func TraceIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceID := r.Header.Get("X-Trace-Id")
+		if traceID == "" {
+			traceID = r.Header.Get("X-Request-Id")
+		}
+		if traceID == "" {
+			traceID = newTraceID()
+		}
+
+		ctx := context.WithValue(r.Context(), traceIDKey{}, traceID)
+		w.Header().Set("X-Trace-Id", traceID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// TraceID returns the trace ID stashed by TraceIDMiddleware, or "" if none
+// is present (e.g. in a test calling a handler directly).
+func TraceID(ctx context.Context) string {
+	id, _ := ctx.Value(traceIDKey{}).(string)
+	return id
+}
+
+func newTraceID() string {
+	var buf [16]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(buf[:])
+}
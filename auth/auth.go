@@ -0,0 +1,86 @@
+// Package auth issues and validates the JWTs POST /auth/register and
+// POST /auth/login hand out, and hashes the passwords backing them. It
+// deliberately knows nothing about HTTP or the database - handlers.AuthAPIServer
+// and handlers.AuthMiddleware are the callers.
+package auth
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrInvalidToken is returned by ParseToken for any malformed, expired, or
+// otherwise invalid token, without distinguishing why - callers only need
+// to know whether to accept the bearer.
+var ErrInvalidToken = errors.New("auth: invalid token")
+
+// tokenTTL is how long an issued JWT remains valid before the caller must
+// log in again.
+const tokenTTL = 24 * time.Hour
+
+// claims is the JWT payload: just enough to recover the authenticated
+// user's id on each request.
+type claims struct {
+	UserID int64 `json:"user_id"`
+	jwt.RegisteredClaims
+}
+
+// signingKey returns the HMAC secret JWTs are signed and verified with.
+// AUTH_JWT_SECRET should always be set in production; the fallback exists
+// so the server still starts for local development and tests, the same
+// convention databaseDSN (see main.go) uses for DATABASE_URL.
+func signingKey() []byte {
+	if secret := os.Getenv("AUTH_JWT_SECRET"); secret != "" {
+		return []byte(secret)
+	}
+	return []byte("dev-only-insecure-signing-key")
+}
+
+// IssueToken mints a signed JWT asserting userID, valid for tokenTTL.
+func IssueToken(userID int64) (string, error) {
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims{
+		UserID: userID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(tokenTTL)),
+		},
+	})
+	return token.SignedString(signingKey())
+}
+
+// ParseToken validates tokenString's signature and expiry and returns the
+// user id it asserts.
+func ParseToken(tokenString string) (userID int64, err error) {
+	var c claims
+	parsed, err := jwt.ParseWithClaims(tokenString, &c, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrInvalidToken
+		}
+		return signingKey(), nil
+	})
+	if err != nil || !parsed.Valid {
+		return 0, ErrInvalidToken
+	}
+	return c.UserID, nil
+}
+
+// HashPassword returns a bcrypt hash of password suitable for storing
+// alongside a user's record.
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// CheckPassword reports whether password matches the bcrypt hash previously
+// produced by HashPassword.
+func CheckPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
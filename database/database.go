@@ -1,82 +1,64 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"errors"
-	"os"
-	"path/filepath" // Added for robust schema path
-	"runtime"       // Added for robust schema path
+	"fmt"
+	"strings"
 
 	"app/models"
-
-	_ "github.com/mattn/go-sqlite3"
 )
 
-// getPackageDir returns the directory of the current Go package.
-func getPackageDir() string {
-	_, b, _, _ := runtime.Caller(0) // Get information about the caller (this file)
-	return filepath.Dir(b)          // Directory of this file (database package)
-}
-
+// InitDB opens a SQLite database at filepathArg (a path, or ":memory:"),
+// bringing its schema up to date via the migrations embedded in this
+// package, and returns the raw *sql.DB. It predates OpenStore and is kept
+// only for the SQLite-specific callers (tests, version_test.go's
+// file-backed concurrency test) that want the concrete *sql.DB rather than
+// a backend-agnostic Store; new code should call OpenStore instead.
 func InitDB(filepathArg string) (*sql.DB, error) {
-	db, err := sql.Open("sqlite3", filepathArg)
+	store, err := OpenStore(filepathArg)
 	if err != nil {
 		return nil, err
 	}
+	return store.(*SQLStore).DB, nil
+}
 
-	// Construct path to schema.sql relative to this file's location (database package directory)
-	// This makes it robust to where the application or tests are run from.
-	schemaPath := filepath.Join(getPackageDir(), "schema.sql")
-
-	schemaSQL, err := os.ReadFile(schemaPath)
-	if err != nil {
-		db.Close()
-		return nil, errors.New("failed to read schema.sql at " + schemaPath + ": " + err.Error())
-	}
+// ErrVersionMismatch is returned by UpdateItem and DeleteItem when the
+// caller's expected version no longer matches the stored row, indicating a
+// lost-update race that the caller (via If-Match) asked to guard against.
+var ErrVersionMismatch = errors.New("database: version mismatch")
 
-	_, err = db.Exec(string(schemaSQL))
-	if err != nil {
-		db.Close()
-		return nil, errors.New("failed to execute schema: " + err.Error())
-	}
-
-	return db, nil
+// DBTX is satisfied by both *sql.DB and *sql.Tx, so every function below can
+// run either directly against the database or inside a caller-managed
+// transaction (see WithTx and handlers.ItemAPIServer.BatchItems). Its
+// methods take a context so a client disconnect or request deadline cancels
+// the underlying query rather than leaking it to completion.
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
 }
 
-// CreateItem, GetItem, GetItems, UpdateItem, DeleteItem functions remain unchanged...
-// (Assuming they are already present from previous steps)
-
 // CreateItem adds a new item to the database.
 // It returns the ID of the newly created item.
-func CreateItem(db *sql.DB, item models.Item) (int64, error) {
-	stmt, err := db.Prepare("INSERT INTO items(name, description, priority) VALUES(?, ?, ?)")
-	if err != nil {
-		return 0, err
-	}
-	defer stmt.Close()
-
-	result, err := stmt.Exec(item.Name, item.Description, item.Priority)
-	if err != nil {
-		return 0, err
-	}
-
-	id, err := result.LastInsertId()
-	if err != nil {
-		return 0, err
-	}
-	return id, nil
+func CreateItem(ctx context.Context, db DBTX, item models.Item) (int64, error) {
+	return dialectOf(db).InsertReturningID(ctx, db,
+		"INSERT INTO items(name, description, priority, owner_user_id) VALUES(?, ?, ?, ?)",
+		item.Name, item.Description, item.Priority, item.OwnerUserID)
 }
 
 // GetItem retrieves a single item from the database by its ID.
-func GetItem(db *sql.DB, id int64) (models.Item, error) {
-	stmt, err := db.Prepare("SELECT id, name, description, priority FROM items WHERE id = ?")
+func GetItem(ctx context.Context, db DBTX, id int64) (models.Item, error) {
+	stmt, err := db.PrepareContext(ctx, "SELECT id, name, description, priority, version, owner_user_id FROM items WHERE id = ?")
 	if err != nil {
 		return models.Item{}, err
 	}
 	defer stmt.Close()
 
 	var item models.Item
-	err = stmt.QueryRow(id).Scan(&item.ID, &item.Name, &item.Description, &item.Priority)
+	err = stmt.QueryRowContext(ctx, id).Scan(&item.ID, &item.Name, &item.Description, &item.Priority, &item.Version, &item.OwnerUserID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return models.Item{}, err
@@ -86,45 +68,152 @@ func GetItem(db *sql.DB, id int64) (models.Item, error) {
 	return item, nil
 }
 
-// GetItems retrieves all items from the database.
-func GetItems(db *sql.DB) ([]models.Item, error) {
-	stmt, err := db.Prepare("SELECT id, name, description, priority FROM items")
-	if err != nil {
-		return nil, err
+// ItemCursor is the keyset position of the last row a caller saw. GetItems
+// returns one alongside each page so that callers can resume immediately
+// after it rather than re-scanning with an OFFSET.
+type ItemCursor struct {
+	LastPriority int
+	LastName     string
+	LastID       int64
+}
+
+// ItemSort selects the column GetItems orders and keyset-paginates by.
+// Column is validated against a fixed allow-list (see ParseItemSort) before
+// ever reaching SQL, since it is interpolated directly into the query.
+type ItemSort struct {
+	Column string
+	Desc   bool
+}
+
+// ParseItemSort maps an API sort query value to an ItemSort, rejecting
+// anything outside the supported set so GetItems never interpolates
+// caller-controlled column names into SQL.
+func ParseItemSort(sort string) (ItemSort, error) {
+	switch sort {
+	case "", "-priority":
+		return ItemSort{Column: "priority", Desc: true}, nil
+	case "priority":
+		return ItemSort{Column: "priority", Desc: false}, nil
+	case "name":
+		return ItemSort{Column: "name", Desc: false}, nil
+	case "-id":
+		return ItemSort{Column: "id", Desc: true}, nil
+	default:
+		return ItemSort{}, fmt.Errorf("unsupported sort %q", sort)
 	}
-	defer stmt.Close()
+}
+
+// ListItemsParams configures the keyset-paginated query GetItems performs.
+type ListItemsParams struct {
+	// Limit is the maximum number of items to return; GetItems fetches one
+	// extra row beyond it to determine HasMore without a second query.
+	Limit int
+	// After is the cursor to resume after, or nil for the first page.
+	After        *ItemCursor
+	Sort         ItemSort
+	NameContains string
+	NamePrefix   string
+	MinPriority  *int
+	MaxPriority  *int
+	// OwnerUserID, when non-nil, restricts results to items owned by that
+	// user (see ItemAPIServer.GetItems in AuthDisabled=false mode).
+	OwnerUserID *int64
+}
+
+// GetItems retrieves a page of items, newest-matching-sort-first, applying
+// filters and keyset pagination entirely in SQL rather than loading
+// everything and paging in memory.
+func GetItems(ctx context.Context, db DBTX, params ListItemsParams) (items []models.Item, hasMore bool, err error) {
+	var where []string
+	var args []interface{}
 
-	rows, err := stmt.Query()
+	if params.NameContains != "" {
+		where = append(where, "name LIKE ?")
+		args = append(args, "%"+params.NameContains+"%")
+	}
+	if params.NamePrefix != "" {
+		where = append(where, "name LIKE ?")
+		args = append(args, params.NamePrefix+"%")
+	}
+	if params.MinPriority != nil {
+		where = append(where, "priority >= ?")
+		args = append(args, *params.MinPriority)
+	}
+	if params.MaxPriority != nil {
+		where = append(where, "priority <= ?")
+		args = append(args, *params.MaxPriority)
+	}
+	if params.OwnerUserID != nil {
+		where = append(where, "owner_user_id = ?")
+		args = append(args, *params.OwnerUserID)
+	}
+
+	op := ">"
+	if params.Sort.Desc {
+		op = "<"
+	}
+	if params.After != nil {
+		switch params.Sort.Column {
+		case "priority":
+			where = append(where, fmt.Sprintf("(priority, id) %s (?, ?)", op))
+			args = append(args, params.After.LastPriority, params.After.LastID)
+		case "name":
+			where = append(where, fmt.Sprintf("(name, id) %s (?, ?)", op))
+			args = append(args, params.After.LastName, params.After.LastID)
+		case "id":
+			where = append(where, fmt.Sprintf("id %s ?", op))
+			args = append(args, params.After.LastID)
+		}
+	}
+
+	query := "SELECT id, name, description, priority, version, owner_user_id FROM items"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	dir := "ASC"
+	if params.Sort.Desc {
+		dir = "DESC"
+	}
+	query += fmt.Sprintf(" ORDER BY %s %s, id %s LIMIT ?", params.Sort.Column, dir, dir)
+	args = append(args, params.Limit+1)
+
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 	defer rows.Close()
 
-	var items []models.Item
 	for rows.Next() {
 		var item models.Item
-		if err := rows.Scan(&item.ID, &item.Name, &item.Description, &item.Priority); err != nil {
-			return nil, err
+		if err := rows.Scan(&item.ID, &item.Name, &item.Description, &item.Priority, &item.Version, &item.OwnerUserID); err != nil {
+			return nil, false, err
 		}
 		items = append(items, item)
 	}
-
 	if err = rows.Err(); err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
-	return items, nil
+	if len(items) > params.Limit {
+		items = items[:params.Limit]
+		hasMore = true
+	}
+	return items, hasMore, nil
 }
 
-// UpdateItem modifies an existing item in the database.
-func UpdateItem(db *sql.DB, id int64, item models.Item) (int64, error) {
-	stmt, err := db.Prepare("UPDATE items SET name = ?, description = ?, priority = ? WHERE id = ?")
+// UpdateItem modifies an existing item in the database, enforcing optimistic
+// concurrency: the row is only updated (and its version bumped) if its
+// current version matches expectedVersion. A mismatch (row exists but was
+// modified since the caller read it) returns ErrVersionMismatch; a missing
+// row returns sql.ErrNoRows.
+func UpdateItem(ctx context.Context, db DBTX, id int64, item models.Item, expectedVersion int64) (int64, error) {
+	stmt, err := db.PrepareContext(ctx, "UPDATE items SET name = ?, description = ?, priority = ?, version = version + 1 WHERE id = ? AND version = ?")
 	if err != nil {
 		return 0, err
 	}
 	defer stmt.Close()
 
-	result, err := stmt.Exec(item.Name, item.Description, item.Priority, id)
+	result, err := stmt.ExecContext(ctx, item.Name, item.Description, item.Priority, id, expectedVersion)
 	if err != nil {
 		return 0, err
 	}
@@ -133,21 +222,22 @@ func UpdateItem(db *sql.DB, id int64, item models.Item) (int64, error) {
 	if err != nil {
 		return 0, err
 	}
-    if rowsAffected == 0 {
-        return 0, sql.ErrNoRows
-    }
+	if rowsAffected == 0 {
+		return 0, versionAwareNotFoundErr(ctx, db, id, expectedVersion)
+	}
 	return rowsAffected, nil
 }
 
-// DeleteItem removes an item from the database by its ID.
-func DeleteItem(db *sql.DB, id int64) (int64, error) {
-	stmt, err := db.Prepare("DELETE FROM items WHERE id = ?")
+// DeleteItem removes an item from the database by its ID, enforcing the same
+// optimistic-concurrency check as UpdateItem.
+func DeleteItem(ctx context.Context, db DBTX, id int64, expectedVersion int64) (int64, error) {
+	stmt, err := db.PrepareContext(ctx, "DELETE FROM items WHERE id = ? AND version = ?")
 	if err != nil {
 		return 0, err
 	}
 	defer stmt.Close()
 
-	result, err := stmt.Exec(id)
+	result, err := stmt.ExecContext(ctx, id, expectedVersion)
 	if err != nil {
 		return 0, err
 	}
@@ -156,8 +246,26 @@ func DeleteItem(db *sql.DB, id int64) (int64, error) {
 	if err != nil {
 		return 0, err
 	}
-    if rowsAffected == 0 {
-        return 0, sql.ErrNoRows
-    }
+	if rowsAffected == 0 {
+		return 0, versionAwareNotFoundErr(ctx, db, id, expectedVersion)
+	}
 	return rowsAffected, nil
 }
+
+// versionAwareNotFoundErr disambiguates a zero-row UPDATE/DELETE: the row
+// may simply not exist (sql.ErrNoRows), or it may exist with a different
+// version than the caller expected (ErrVersionMismatch).
+func versionAwareNotFoundErr(ctx context.Context, db DBTX, id int64, expectedVersion int64) error {
+	var currentVersion int64
+	err := db.QueryRowContext(ctx, "SELECT version FROM items WHERE id = ?", id).Scan(&currentVersion)
+	if errors.Is(err, sql.ErrNoRows) {
+		return sql.ErrNoRows
+	}
+	if err != nil {
+		return err
+	}
+	if currentVersion != expectedVersion {
+		return ErrVersionMismatch
+	}
+	return sql.ErrNoRows
+}
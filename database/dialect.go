@@ -0,0 +1,52 @@
+package database
+
+import "context"
+
+// Dialect isolates the one piece of per-backend SQL behavior placeholder
+// rebinding (see rebindingConn) doesn't cover: recovering the id of a row an
+// INSERT just created. SQLite and MySQL hand that back via
+// sql.Result.LastInsertId; Postgres's driver doesn't implement it at all, so
+// an INSERT there needs a RETURNING id clause and a row scan instead.
+type Dialect interface {
+	// InsertReturningID runs query (an INSERT with no RETURNING clause of
+	// its own) against conn and returns the id of the row it created.
+	InsertReturningID(ctx context.Context, conn DBTX, query string, args ...interface{}) (int64, error)
+}
+
+// lastInsertIDDialect is used for SQLite and MySQL, both of which populate
+// sql.Result.LastInsertId after a plain INSERT.
+type lastInsertIDDialect struct{}
+
+func (lastInsertIDDialect) InsertReturningID(ctx context.Context, conn DBTX, query string, args ...interface{}) (int64, error) {
+	result, err := conn.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// returningIDDialect is used for Postgres, whose driver leaves
+// sql.Result.LastInsertId unimplemented; appending RETURNING id and reading
+// it back from the query row is the idiomatic replacement.
+type returningIDDialect struct{}
+
+func (returningIDDialect) InsertReturningID(ctx context.Context, conn DBTX, query string, args ...interface{}) (int64, error) {
+	var id int64
+	err := conn.QueryRowContext(ctx, query+" RETURNING id", args...).Scan(&id)
+	return id, err
+}
+
+// dialectHolder is implemented by conn wrappers (see rebindingConn and
+// tracingConn) that know which Dialect their backend needs. dialectOf falls
+// back to lastInsertIDDialect for a plain *sql.DB/*sql.Tx, preserving how
+// this package has always behaved against SQLite and MySQL.
+type dialectHolder interface {
+	Dialect() Dialect
+}
+
+func dialectOf(conn DBTX) Dialect {
+	if dh, ok := conn.(dialectHolder); ok {
+		return dh.Dialect()
+	}
+	return lastInsertIDDialect{}
+}
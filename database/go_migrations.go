@@ -0,0 +1,102 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+)
+
+// GoMigration is a schema or data change that SQL can't express (e.g. a
+// backfill that needs Go logic), applied after the embedded SQL migrations
+// in migrations/ by migrateUp. Version must not collide with any SQL
+// migration's numbered prefix; Go migrations are tracked independently, in
+// the go_migrations table.
+type GoMigration struct {
+	Version int
+	Run     func(tx *sql.Tx) error
+}
+
+// registeredGoMigrations holds every migration added via RegisterGoMigration,
+// applied in ascending Version order.
+var registeredGoMigrations []GoMigration
+
+// RegisterGoMigration adds m to the set of Go-func migrations migrateUp
+// applies on top of the embedded SQL migrations. Call it from an init() in
+// the package defining the migration, before any Store is opened.
+func RegisterGoMigration(m GoMigration) {
+	registeredGoMigrations = append(registeredGoMigrations, m)
+}
+
+// applyGoMigrations runs every registered Go migration not yet recorded in
+// go_migrations, in ascending Version order, each inside its own
+// transaction. A migration that fails is recorded dirty, and
+// applyGoMigrations refuses to run anything further (on this or any later
+// call) until that row is cleared by hand - the same fail-safe golang-migrate
+// applies to the SQL migrations it tracks.
+func applyGoMigrations(db *sql.DB) error {
+	if len(registeredGoMigrations) == 0 {
+		return nil
+	}
+
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS go_migrations (
+		version INTEGER PRIMARY KEY,
+		dirty    BOOLEAN NOT NULL DEFAULT 0
+	)`); err != nil {
+		return fmt.Errorf("creating go_migrations table: %w", err)
+	}
+
+	var dirtyVersion int
+	err := db.QueryRow(`SELECT version FROM go_migrations WHERE dirty = 1 LIMIT 1`).Scan(&dirtyVersion)
+	if err == nil {
+		return fmt.Errorf("go migration %d previously failed and was left dirty; force-clear go_migrations before retrying", dirtyVersion)
+	} else if err != sql.ErrNoRows {
+		return fmt.Errorf("checking for a dirty go migration: %w", err)
+	}
+
+	sorted := append([]GoMigration(nil), registeredGoMigrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+
+	for _, gm := range sorted {
+		var applied bool
+		err := db.QueryRow(`SELECT 1 FROM go_migrations WHERE version = ?`, gm.Version).Scan(new(int))
+		applied = err == nil
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("checking whether go migration %d has run: %w", gm.Version, err)
+		}
+		if applied {
+			continue
+		}
+
+		if err := runGoMigration(db, gm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runGoMigration applies a single Go migration inside its own transaction,
+// recording it as dirty before running so a crash mid-migration is caught by
+// the dirty check above, then clearing the dirty flag on success.
+func runGoMigration(db *sql.DB, gm GoMigration) error {
+	if _, err := db.Exec(`INSERT INTO go_migrations(version, dirty) VALUES(?, 1)`, gm.Version); err != nil {
+		return fmt.Errorf("marking go migration %d dirty: %w", gm.Version, err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning go migration %d: %w", gm.Version, err)
+	}
+
+	if err := gm.Run(tx); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("running go migration %d: %w", gm.Version, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing go migration %d: %w", gm.Version, err)
+	}
+
+	if _, err := db.Exec(`UPDATE go_migrations SET dirty = 0 WHERE version = ?`, gm.Version); err != nil {
+		return fmt.Errorf("clearing dirty flag for go migration %d: %w", gm.Version, err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package database
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoMigrationsApplyOnce(t *testing.T) {
+	var runs int
+	registeredGoMigrations = nil
+	t.Cleanup(func() { registeredGoMigrations = nil })
+
+	RegisterGoMigration(GoMigration{
+		Version: 9001,
+		Run: func(tx *sql.Tx) error {
+			runs++
+			_, err := tx.Exec(`CREATE TABLE go_migration_marker (id INTEGER PRIMARY KEY)`)
+			return err
+		},
+	})
+
+	db, err := InitDB(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	assert.Equal(t, 1, runs, "a freshly applied go migration should run exactly once")
+
+	// Re-running migrateUp against the same db must not re-apply it.
+	require.NoError(t, MigrateUp(db))
+	assert.Equal(t, 1, runs, "an already-applied go migration must not run again")
+}
+
+func TestGoMigrationFailureStaysDirty(t *testing.T) {
+	registeredGoMigrations = nil
+	t.Cleanup(func() { registeredGoMigrations = nil })
+
+	RegisterGoMigration(GoMigration{
+		Version: 9002,
+		Run: func(tx *sql.Tx) error {
+			return assert.AnError
+		},
+	})
+
+	// A file-backed database, so the go_migrations row survives InitDB
+	// closing its connection and can be read back below.
+	path := filepath.Join(t.TempDir(), "dirty_go_migration.sqlite3")
+	_, err := InitDB(path)
+	require.Error(t, err, "a failing go migration should surface its error")
+
+	db, err := sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	defer db.Close()
+	var dirty bool
+	require.NoError(t, db.QueryRow(`SELECT dirty FROM go_migrations WHERE version = 9002`).Scan(&dirty))
+	assert.True(t, dirty, "a failed go migration should stay recorded as dirty")
+}
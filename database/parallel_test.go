@@ -0,0 +1,37 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"app/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParallelTestStoresAreIsolated runs several subtests concurrently via
+// SetupTestDBParallel, each writing a different number of items, and checks
+// that every subtest only ever sees its own writes - i.e. that the
+// per-test, shared-cache SQLite DSN (see sqliteTestDSN) doesn't leak data
+// between tests running at the same time.
+func TestParallelTestStoresAreIsolated(t *testing.T) {
+	counts := []int{1, 2, 3, 4}
+	for _, n := range counts {
+		n := n
+		t.Run(fmt.Sprintf("items=%d", n), func(t *testing.T) {
+			store := SetupTestDBParallel(t)
+			ctx := context.Background()
+
+			for i := 0; i < n; i++ {
+				_, err := store.CreateItem(ctx, models.Item{Name: "item", Priority: i})
+				require.NoError(t, err)
+			}
+
+			items, _, err := store.GetItems(ctx, ListItemsParams{Limit: 100, Sort: ItemSort{Column: "id"}})
+			require.NoError(t, err)
+			assert.Len(t, items, n, "this subtest's store should only contain the items it created itself")
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"app/models"
+)
+
+// Store is the handle application code uses to reach the database,
+// independent of backend. It embeds DBTX, so the package-level CreateItem,
+// GetItem, GetItems, UpdateItem, and DeleteItem functions (which already
+// take a DBTX) accept a Store directly, and it exposes BeginTx/Close so
+// WithTx (and, through it, handlers.ItemAPIServer.BatchItems) can still
+// scope a batch of writes to a single transaction regardless of which
+// backend is configured. OpenStore is the only way to obtain one.
+type Store interface {
+	DBTX
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+	Close() error
+
+	CreateItem(ctx context.Context, item models.Item) (int64, error)
+	GetItem(ctx context.Context, id int64) (models.Item, error)
+	GetItems(ctx context.Context, params ListItemsParams) (items []models.Item, hasMore bool, err error)
+	UpdateItem(ctx context.Context, id int64, item models.Item, expectedVersion int64) (int64, error)
+	DeleteItem(ctx context.Context, id int64, expectedVersion int64) (int64, error)
+
+	// TxConn wraps a transaction begun via BeginTx with the same
+	// backend-specific placeholder rebinding and tracing that the CRUD
+	// methods above get through conn, so code running inside a transaction
+	// (see WithTx) gets identical behavior to code running directly against
+	// the Store.
+	TxConn(tx *sql.Tx) DBTX
+}
+
+// SQLStore is the Store implementation OpenStore returns. It is written
+// purely in terms of *sql.DB, and wrap rebinds placeholders when backend is
+// Postgres; OpenStore opens only SQLite and rejects Postgres and MySQL DSNs
+// (see its doc comment). Embedding *sql.DB gives it
+// ExecContext/QueryContext/QueryRowContext/PrepareContext/Close for free,
+// satisfying DBTX plus the rest of Store without repeating those method
+// bodies; BeginTx is implemented explicitly below to match Store's ctx-first
+// signature.
+type SQLStore struct {
+	*sql.DB
+	backend backend
+}
+
+func (s *SQLStore) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return s.DB.BeginTx(ctx, opts)
+}
+
+func (s *SQLStore) conn() DBTX {
+	return s.wrap(s.DB)
+}
+
+func (s *SQLStore) TxConn(tx *sql.Tx) DBTX {
+	return s.wrap(tx)
+}
+
+func (s *SQLStore) wrap(conn DBTX) DBTX {
+	if s.backend == backendPostgres {
+		conn = rebindingConn{conn}
+	}
+	return tracingConn{conn}
+}
+
+func (s *SQLStore) CreateItem(ctx context.Context, item models.Item) (int64, error) {
+	return CreateItem(ctx, s.conn(), item)
+}
+
+func (s *SQLStore) GetItem(ctx context.Context, id int64) (models.Item, error) {
+	return GetItem(ctx, s.conn(), id)
+}
+
+func (s *SQLStore) GetItems(ctx context.Context, params ListItemsParams) ([]models.Item, bool, error) {
+	return GetItems(ctx, s.conn(), params)
+}
+
+func (s *SQLStore) UpdateItem(ctx context.Context, id int64, item models.Item, expectedVersion int64) (int64, error) {
+	return UpdateItem(ctx, s.conn(), id, item, expectedVersion)
+}
+
+func (s *SQLStore) DeleteItem(ctx context.Context, id int64, expectedVersion int64) (int64, error) {
+	return DeleteItem(ctx, s.conn(), id, expectedVersion)
+}
+
+// rebindingConn wraps a DBTX and rewrites this package's `?`-style
+// placeholders to Postgres's `$1, $2, ...` before delegating, so the
+// queries in database.go and friends are written once and work against
+// every backend. It is only used for the Postgres backend; SQLite and MySQL
+// both accept `?` natively.
+type rebindingConn struct {
+	DBTX
+}
+
+func (c rebindingConn) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return c.DBTX.ExecContext(ctx, rebindPlaceholders(query), args...)
+}
+
+func (c rebindingConn) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return c.DBTX.QueryContext(ctx, rebindPlaceholders(query), args...)
+}
+
+func (c rebindingConn) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return c.DBTX.QueryRowContext(ctx, rebindPlaceholders(query), args...)
+}
+
+func (c rebindingConn) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return c.DBTX.PrepareContext(ctx, rebindPlaceholders(query))
+}
+
+// Dialect reports that rebindingConn is only ever used for Postgres (see
+// wrap), whose driver needs a RETURNING id clause rather than LastInsertId.
+func (c rebindingConn) Dialect() Dialect {
+	return returningIDDialect{}
+}
+
+// rebindPlaceholders replaces every `?` in query, in order, with `$1`,
+// `$2`, and so on.
+func rebindPlaceholders(query string) string {
+	if !strings.Contains(query, "?") {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			fmt.Fprintf(&b, "$%d", n)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,100 @@
+// Package testfixtures loads declarative fixture files into a test database,
+// so repository tests can describe known-good state once ("items_basic.yaml")
+// and reuse it instead of hand-building rows via database.CreateItem in every
+// test.
+package testfixtures
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DBTX is the minimal surface Load needs to populate a database - satisfied
+// by *sql.DB, *sql.Tx, and app/database.DBTX.
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// Load populates db from the fixture file at path. A ".sql" file is executed
+// verbatim as a sequence of ";"-separated statements. A ".yaml"/".yml" file
+// is a mapping of table name to a list of rows, each row a mapping of column
+// name to value (see database/testdata/items_basic.yaml for an example);
+// Load inserts tables in alphabetical order, rows in file order.
+func Load(ctx context.Context, db DBTX, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("testfixtures: reading %s: %w", path, err)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".sql":
+		return loadSQL(ctx, db, data)
+	case ".yaml", ".yml":
+		return loadYAML(ctx, db, data)
+	default:
+		return fmt.Errorf("testfixtures: %s: unsupported extension %q (want .sql, .yaml, or .yml)", path, ext)
+	}
+}
+
+func loadSQL(ctx context.Context, db DBTX, data []byte) error {
+	for _, stmt := range strings.Split(string(data), ";") {
+		stmt = strings.TrimSpace(stmt)
+		if stmt == "" {
+			continue
+		}
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("testfixtures: executing %q: %w", stmt, err)
+		}
+	}
+	return nil
+}
+
+func loadYAML(ctx context.Context, db DBTX, data []byte) error {
+	var tables map[string][]map[string]interface{}
+	if err := yaml.Unmarshal(data, &tables); err != nil {
+		return fmt.Errorf("testfixtures: parsing YAML: %w", err)
+	}
+
+	tableNames := make([]string, 0, len(tables))
+	for name := range tables {
+		tableNames = append(tableNames, name)
+	}
+	sort.Strings(tableNames)
+
+	for _, table := range tableNames {
+		for _, row := range tables[table] {
+			if err := insertRow(ctx, db, table, row); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func insertRow(ctx context.Context, db DBTX, table string, row map[string]interface{}) error {
+	columns := make([]string, 0, len(row))
+	for col := range row {
+		columns = append(columns, col)
+	}
+	sort.Strings(columns)
+
+	placeholders := make([]string, len(columns))
+	args := make([]interface{}, len(columns))
+	for i, col := range columns {
+		placeholders[i] = "?"
+		args[i] = row[col]
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s(%s) VALUES(%s)", table, strings.Join(columns, ", "), strings.Join(placeholders, ", "))
+	if _, err := db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("testfixtures: inserting into %s: %w", table, err)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package database
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+// testNameReplacer sanitizes a test name (which may contain "/" from
+// subtests, or spaces from a t.Run name) into something safe to embed in a
+// SQLite URI.
+var testNameReplacer = strings.NewReplacer("/", "_", " ", "_")
+
+// sqliteTestDSN derives a SQLite shared-cache, in-memory URI unique to t:
+// the test name plus a hash of the working directory (so two packages'
+// tests, run concurrently by `go test ./...`, can't collide even if they
+// happen to share a test name). Unlike a bare ":memory:" DSN - where every
+// connection gets its own private database - "cache=shared" lets multiple
+// connections opened by the same test (e.g. concurrent goroutines) see the
+// same data, the same problem setupVersionTestDB works around with a
+// temp-file database (see version_test.go).
+func sqliteTestDSN(t testing.TB) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		wd = "unknown-wd"
+	}
+	h := sha1.Sum([]byte(wd))
+	name := testNameReplacer.Replace(t.Name())
+	return fmt.Sprintf("file:%s_%x?mode=memory&cache=shared", name, h[:4])
+}
+
+// NewTestStore opens a migrated, uniquely-isolated Store for t - safe to use
+// from parallel tests (see SetupTestDBParallel) since sqliteTestDSN
+// guarantees no two tests share a database. The Store is closed
+// automatically via t.Cleanup.
+func NewTestStore(t testing.TB) Store {
+	t.Helper()
+	store, err := OpenStore(sqliteTestDSN(t))
+	if err != nil {
+		t.Fatalf("opening test store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// SetupTestDBParallel marks t as safe to run in parallel with its siblings
+// and returns an isolated Store for it, per NewTestStore.
+func SetupTestDBParallel(t *testing.T) Store {
+	t.Helper()
+	t.Parallel()
+	return NewTestStore(t)
+}
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies this package's spans in exported traces.
+const tracerName = "app/database"
+
+// tracingConn wraps a DBTX so every ExecContext/QueryContext/QueryRowContext/
+// PrepareContext call opens a child span carrying the SQL statement as a
+// "db.statement" attribute. It is layered onto SQLStore's CRUD methods (see
+// conn and TxConn in store.go), so every call - whether made directly
+// against a Store or inside a WithTx transaction - nests under the calling
+// request's span, since ctx now flows all the way down from the handler.
+type tracingConn struct {
+	DBTX
+}
+
+func (c tracingConn) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := startSpan(ctx, query)
+	defer span.End()
+	result, err := c.DBTX.ExecContext(ctx, query, args...)
+	recordErr(span, err)
+	return result, err
+}
+
+func (c tracingConn) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, span := startSpan(ctx, query)
+	defer span.End()
+	rows, err := c.DBTX.QueryContext(ctx, query, args...)
+	recordErr(span, err)
+	return rows, err
+}
+
+func (c tracingConn) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, span := startSpan(ctx, query)
+	defer span.End()
+	return c.DBTX.QueryRowContext(ctx, query, args...)
+}
+
+func (c tracingConn) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	ctx, span := startSpan(ctx, query)
+	defer span.End()
+	stmt, err := c.DBTX.PrepareContext(ctx, query)
+	recordErr(span, err)
+	return stmt, err
+}
+
+// Dialect forwards to the conn tracingConn wraps, if that conn knows its
+// dialect (see rebindingConn), and otherwise defaults to lastInsertIDDialect
+// - tracingConn itself is dialect-agnostic, just tracking whichever it's
+// layered onto (see SQLStore.wrap).
+func (c tracingConn) Dialect() Dialect {
+	if dh, ok := c.DBTX.(dialectHolder); ok {
+		return dh.Dialect()
+	}
+	return lastInsertIDDialect{}
+}
+
+func startSpan(ctx context.Context, query string) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, "database.query",
+		trace.WithAttributes(attribute.String("db.statement", query)),
+	)
+}
+
+func recordErr(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
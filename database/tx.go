@@ -0,0 +1,42 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+)
+
+// txKey is the context key WithTx stores its *sql.Tx under, so a nested
+// WithTx call (e.g. a helper that itself calls WithTx, invoked from inside
+// another WithTx callback) can detect and reuse the ambient transaction
+// instead of trying to open a second one.
+type txKey struct{}
+
+// WithTx runs fn inside a transaction, committing if fn returns nil and
+// rolling back otherwise. The DBTX passed to fn is the transaction wrapped
+// with the same placeholder rebinding and tracing the Store's own CRUD
+// methods get (see Store.TxConn), so callers that need direct access to
+// *sql.Tx (e.g. for SAVEPOINT) can still reach it via the tx parameter.
+//
+// If ctx already carries a transaction from an enclosing WithTx call, that
+// transaction is reused rather than opening a nested one - opts is ignored
+// in that case, since the outer call already chose its options, and none of
+// SQLite, Postgres, or MySQL support true nested transactions anyway. The
+// inner fn's error still aborts the whole thing: it propagates up to the
+// outer WithTx's rollback rather than being swallowed.
+func WithTx(ctx context.Context, store Store, opts *sql.TxOptions, fn func(ctx context.Context, tx *sql.Tx, conn DBTX) error) error {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx, tx, store.TxConn(tx))
+	}
+
+	tx, err := store.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	ctx = context.WithValue(ctx, txKey{}, tx)
+	if err := fn(ctx, tx, store.TxConn(tx)); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"app/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openTestStore(t *testing.T) Store {
+	return NewTestStore(t)
+}
+
+func TestWithTxRollsBackOnError(t *testing.T) {
+	store := openTestStore(t)
+	sentinel := errors.New("boom")
+
+	err := WithTx(context.Background(), store, nil, func(ctx context.Context, tx *sql.Tx, conn DBTX) error {
+		if _, err := CreateItem(ctx, conn, models.Item{Name: "should not persist", Priority: 1}); err != nil {
+			return err
+		}
+		return sentinel
+	})
+	require.ErrorIs(t, err, sentinel)
+
+	items, _, err := store.GetItems(context.Background(), ListItemsParams{Limit: 10, Sort: ItemSort{Column: "id"}})
+	require.NoError(t, err)
+	assert.Empty(t, items, "a rolled-back WithTx must leave no trace")
+}
+
+func TestWithTxCommitsOnSuccess(t *testing.T) {
+	store := openTestStore(t)
+
+	err := WithTx(context.Background(), store, nil, func(ctx context.Context, tx *sql.Tx, conn DBTX) error {
+		_, err := CreateItem(ctx, conn, models.Item{Name: "persisted", Priority: 1})
+		return err
+	})
+	require.NoError(t, err)
+
+	items, _, err := store.GetItems(context.Background(), ListItemsParams{Limit: 10, Sort: ItemSort{Column: "id"}})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "persisted", items[0].Name)
+}
+
+func TestWithTxAbortsOnContextCancellation(t *testing.T) {
+	store := openTestStore(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := WithTx(ctx, store, nil, func(ctx context.Context, tx *sql.Tx, conn DBTX) error {
+		_, err := CreateItem(ctx, conn, models.Item{Name: "never", Priority: 1})
+		return err
+	})
+	require.Error(t, err, "a query against an already-cancelled context should fail")
+
+	items, _, err := store.GetItems(context.Background(), ListItemsParams{Limit: 10, Sort: ItemSort{Column: "id"}})
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
+
+func TestWithTxNestedReusesAmbientTx(t *testing.T) {
+	store := openTestStore(t)
+
+	var innerTx *sql.Tx
+	err := WithTx(context.Background(), store, nil, func(ctx context.Context, outerTx *sql.Tx, conn DBTX) error {
+		return WithTx(ctx, store, nil, func(ctx context.Context, tx *sql.Tx, conn DBTX) error {
+			innerTx = tx
+			_, err := CreateItem(ctx, conn, models.Item{Name: "nested", Priority: 1})
+			return err
+		})
+	})
+	require.NoError(t, err)
+	require.NotNil(t, innerTx)
+
+	items, _, err := store.GetItems(context.Background(), ListItemsParams{Limit: 10, Sort: ItemSort{Column: "id"}})
+	require.NoError(t, err)
+	require.Len(t, items, 1, "the nested call's write should be committed by the outer WithTx")
+}
+
+func TestWithTxNestedRollsBackOnInnerError(t *testing.T) {
+	store := openTestStore(t)
+	sentinel := errors.New("inner boom")
+
+	err := WithTx(context.Background(), store, nil, func(ctx context.Context, outerTx *sql.Tx, conn DBTX) error {
+		if _, err := CreateItem(ctx, conn, models.Item{Name: "outer write", Priority: 1}); err != nil {
+			return err
+		}
+		return WithTx(ctx, store, nil, func(ctx context.Context, tx *sql.Tx, conn DBTX) error {
+			return sentinel
+		})
+	})
+	require.ErrorIs(t, err, sentinel)
+
+	items, _, err := store.GetItems(context.Background(), ListItemsParams{Limit: 10, Sort: ItemSort{Column: "id"}})
+	require.NoError(t, err)
+	assert.Empty(t, items, "an inner failure must roll back the outer write too")
+}
@@ -0,0 +1,82 @@
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+
+	"app/models"
+)
+
+// ErrUsernameTaken is returned by CreateUserWithCredentials when username is
+// already registered.
+var ErrUsernameTaken = errors.New("database: username already taken")
+
+// CreateUser registers a new user and issues an opaque bearer token, which
+// AuthMiddleware accepts alongside the JWTs issued by POST /auth/login.
+func CreateUser(ctx context.Context, db DBTX) (models.User, error) {
+	token, err := generateToken()
+	if err != nil {
+		return models.User{}, err
+	}
+
+	id, err := dialectOf(db).InsertReturningID(ctx, db, "INSERT INTO users(token) VALUES(?)", token)
+	if err != nil {
+		return models.User{}, err
+	}
+	return models.User{ID: id, Token: token}, nil
+}
+
+// GetUserByToken resolves a bearer token to its owning user id.
+func GetUserByToken(ctx context.Context, db DBTX, token string) (int64, error) {
+	var id int64
+	err := db.QueryRowContext(ctx, "SELECT id FROM users WHERE token = ?", token).Scan(&id)
+	return id, err
+}
+
+// CreateUserWithCredentials registers a new user authenticated by username
+// and password rather than an issued token (see auth.HashPassword for
+// producing passwordHash); the caller logs in via GetUserByUsername and
+// auth.IssueToken afterward rather than being handed a token at creation.
+// It still generates and stores an opaque token alongside the credentials,
+// since the users table's token column is NOT NULL UNIQUE; that token is
+// never handed back to the client, which authenticates with a JWT instead.
+func CreateUserWithCredentials(ctx context.Context, db DBTX, username, passwordHash string) (models.User, error) {
+	if _, err := GetUserByUsername(ctx, db, username); err == nil {
+		return models.User{}, ErrUsernameTaken
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return models.User{}, err
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return models.User{}, err
+	}
+
+	id, err := dialectOf(db).InsertReturningID(ctx, db,
+		"INSERT INTO users(token, username, password_hash) VALUES(?, ?, ?)", token, username, passwordHash)
+	if err != nil {
+		return models.User{}, err
+	}
+	return models.User{ID: id, Token: token, Username: username, PasswordHash: passwordHash}, nil
+}
+
+// GetUserByUsername retrieves the user registered under username, for
+// POST /auth/login to check the supplied password against.
+func GetUserByUsername(ctx context.Context, db DBTX, username string) (models.User, error) {
+	var user models.User
+	err := db.QueryRowContext(ctx, "SELECT id, username, password_hash FROM users WHERE username = ?", username).
+		Scan(&user.ID, &user.Username, &user.PasswordHash)
+	return user, err
+}
+
+// generateToken produces a random, unguessable opaque token.
+func generateToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,73 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"app/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+// setupVersionTestDB opens a file-backed (not :memory:) database so that
+// concurrent goroutines sharing *sql.DB see the same data, which an
+// in-memory SQLite connection pool does not guarantee.
+func setupVersionTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "version_test.sqlite3")
+	db, err := InitDB(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// TestUpdateItemContention fires concurrent UpdateItem calls against the same
+// row, all racing against each other with the version they originally read,
+// and asserts that exactly one of them wins while the rest see
+// ErrVersionMismatch.
+func TestUpdateItemContention(t *testing.T) {
+	db := setupVersionTestDB(t)
+
+	id, err := CreateItem(context.Background(), db, models.Item{Name: "Contended Item", Description: "", Priority: 1})
+	require.NoError(t, err)
+
+	const attempts = 10
+	var wg sync.WaitGroup
+	results := make(chan error, attempts)
+
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := UpdateItem(context.Background(), db, id, models.Item{
+				Name:     "Updated Item",
+				Priority: i + 1,
+			}, 1) // every goroutine reads the same starting version
+			results <- err
+		}(i)
+	}
+	wg.Wait()
+	close(results)
+
+	var wins, conflicts int
+	for err := range results {
+		switch {
+		case err == nil:
+			wins++
+		case err == ErrVersionMismatch:
+			conflicts++
+		default:
+			t.Fatalf("unexpected error from UpdateItem: %v", err)
+		}
+	}
+
+	require.Equal(t, 1, wins, "exactly one concurrent update should win")
+	require.Equal(t, attempts-1, conflicts, "the rest should lose to a version mismatch")
+
+	final, err := GetItem(context.Background(), db, id)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), final.Version, "version should have been bumped exactly once")
+}
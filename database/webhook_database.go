@@ -0,0 +1,95 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"app/models"
+)
+
+// CreateWebhook registers a new webhook subscription and returns its ID.
+func CreateWebhook(ctx context.Context, db DBTX, sub models.WebhookSubscription) (int64, error) {
+	return dialectOf(db).InsertReturningID(ctx, db,
+		"INSERT INTO webhooks(url, events, secret) VALUES(?, ?, ?)",
+		sub.URL, strings.Join(sub.Events, ","), sub.Secret)
+}
+
+// GetWebhooks retrieves every registered webhook subscription.
+func GetWebhooks(ctx context.Context, db DBTX) ([]models.WebhookSubscription, error) {
+	rows, err := db.QueryContext(ctx, "SELECT id, url, events, secret, created_at FROM webhooks ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []models.WebhookSubscription
+	for rows.Next() {
+		var sub models.WebhookSubscription
+		var events, createdAt string
+		if err := rows.Scan(&sub.ID, &sub.URL, &events, &sub.Secret, &createdAt); err != nil {
+			return nil, err
+		}
+		if events != "" {
+			sub.Events = strings.Split(events, ",")
+		}
+		if parsed, err := time.Parse("2006-01-02T15:04:05.999Z", createdAt); err == nil {
+			sub.CreatedAt = parsed
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+// DeleteWebhook removes a webhook subscription by ID. It reports via
+// rowsAffected whether a subscription actually existed.
+func DeleteWebhook(ctx context.Context, db DBTX, id int64) (rowsAffected int64, err error) {
+	result, err := db.ExecContext(ctx, "DELETE FROM webhooks WHERE id = ?", id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// CreateWebhookDeadLetter records a delivery that exhausted every retry.
+func CreateWebhookDeadLetter(ctx context.Context, db DBTX, webhookID int64, event, payload, lastError string) error {
+	_, err := db.ExecContext(ctx,
+		"INSERT INTO webhook_dead_letters(webhook_id, event, payload, last_error) VALUES(?, ?, ?, ?)",
+		webhookID, event, payload, lastError,
+	)
+	return err
+}
+
+// WebhookDeadLetter is one exhausted-retry delivery recorded by
+// CreateWebhookDeadLetter.
+type WebhookDeadLetter struct {
+	ID        int64
+	WebhookID int64
+	Event     string
+	Payload   string
+	LastError string
+	FailedAt  time.Time
+}
+
+// GetWebhookDeadLetters retrieves every dead-lettered delivery, newest last.
+func GetWebhookDeadLetters(ctx context.Context, db DBTX) ([]WebhookDeadLetter, error) {
+	rows, err := db.QueryContext(ctx, "SELECT id, webhook_id, event, payload, last_error, failed_at FROM webhook_dead_letters ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var letters []WebhookDeadLetter
+	for rows.Next() {
+		var dl WebhookDeadLetter
+		var failedAt string
+		if err := rows.Scan(&dl.ID, &dl.WebhookID, &dl.Event, &dl.Payload, &dl.LastError, &failedAt); err != nil {
+			return nil, err
+		}
+		if parsed, err := time.Parse("2006-01-02T15:04:05.999Z", failedAt); err == nil {
+			dl.FailedAt = parsed
+		}
+		letters = append(letters, dl)
+	}
+	return letters, rows.Err()
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"app/apperrors"
+	"app/auth"
+	"app/database"
+)
+
+// credentials is the POST /auth/register and POST /auth/login request body.
+type credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// authToken is the response body both endpoints return: a JWT to send as
+// `Authorization: Bearer <token>` against AuthMiddleware-gated routes.
+type authToken struct {
+	Token string `json:"token"`
+}
+
+// AuthAPIServer implements the username/password login and registration
+// endpoints. Unlike UserAPIServer's POST /users (which hands out an opaque
+// token at creation time with no password), these issue a JWT - see
+// AuthMiddleware, which accepts either kind of bearer.
+type AuthAPIServer struct {
+	DB database.Store
+}
+
+// NewAuthAPIServer creates a new AuthAPIServer.
+func NewAuthAPIServer(db database.Store) *AuthAPIServer {
+	return &AuthAPIServer{DB: db}
+}
+
+// Register implements the logic for the (POST /auth/register) endpoint.
+func (s *AuthAPIServer) Register(w http.ResponseWriter, r *http.Request) {
+	var creds credentials
+	if !decodeBody(w, r, &creds) {
+		return
+	}
+
+	if violations := validateCredentials(creds); len(violations) > 0 {
+		apperrors.Write(w, r, apperrors.Validation("auth.validation_failed", violations))
+		return
+	}
+
+	passwordHash, err := auth.HashPassword(creds.Password)
+	if err != nil {
+		apperrors.Write(w, r, apperrors.Internal("auth.register_failed"))
+		return
+	}
+
+	user, err := database.CreateUserWithCredentials(r.Context(), s.DB, creds.Username, passwordHash)
+	if err != nil {
+		if errors.Is(err, database.ErrUsernameTaken) {
+			apperrors.Write(w, r, apperrors.Conflict("auth.username_taken", "that username is already registered"))
+			return
+		}
+		apperrors.Write(w, r, apperrors.Internal("auth.register_failed"))
+		return
+	}
+
+	token, err := auth.IssueToken(user.ID)
+	if err != nil {
+		apperrors.Write(w, r, apperrors.Internal("auth.register_failed"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(authToken{Token: token})
+}
+
+// Login implements the logic for the (POST /auth/login) endpoint.
+func (s *AuthAPIServer) Login(w http.ResponseWriter, r *http.Request) {
+	var creds credentials
+	if !decodeBody(w, r, &creds) {
+		return
+	}
+
+	user, err := database.GetUserByUsername(r.Context(), s.DB, creds.Username)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			apperrors.Write(w, r, apperrors.Internal("auth.login_failed"))
+			return
+		}
+		// Same response as a wrong password: distinguishing "no such user"
+		// lets an attacker enumerate registered usernames.
+		apperrors.Write(w, r, apperrors.Unauthorized("auth.invalid_credentials", "username or password is incorrect"))
+		return
+	}
+	if !auth.CheckPassword(user.PasswordHash, creds.Password) {
+		apperrors.Write(w, r, apperrors.Unauthorized("auth.invalid_credentials", "username or password is incorrect"))
+		return
+	}
+
+	token, err := auth.IssueToken(user.ID)
+	if err != nil {
+		apperrors.Write(w, r, apperrors.Internal("auth.login_failed"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(authToken{Token: token})
+}
+
+// validateCredentials collects every field violation on a registration
+// payload, mirroring validateNewItem's collect-everything style.
+func validateCredentials(creds credentials) []apperrors.FieldError {
+	var violations []apperrors.FieldError
+	if creds.Username == "" {
+		violations = append(violations, apperrors.FieldError{Field: "username", Reason: "must not be empty"})
+	}
+	if len(creds.Password) < 8 {
+		violations = append(violations, apperrors.FieldError{Field: "password", Reason: "must be at least 8 characters"})
+	}
+	return violations
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"app/database"
+	"app/internal/generated/openapi"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupAuthTestRouter wires AuthMiddleware in front of an ItemAPIServer with
+// ownership enforcement enabled, plus the unauthenticated /users endpoint,
+// unlike setupTestRouter's AuthDisabled default.
+func setupAuthTestRouter(db database.Store) *chi.Mux {
+	router := chi.NewRouter()
+
+	openapi.UserHandlerWithOptions(NewUserAPIServer(db), openapi.UserChiServerOptions{BaseRouter: router})
+
+	authAPIServer := NewAuthAPIServer(db)
+	router.Post("/auth/register", authAPIServer.Register)
+	router.Post("/auth/login", authAPIServer.Login)
+
+	itemAPIServer := NewItemAPIServer(db)
+	itemAPIServer.AuthDisabled = false
+
+	router.Group(func(r chi.Router) {
+		r.Use(AuthMiddleware(db))
+		openapi.HandlerWithOptions(itemAPIServer, openapi.ChiServerOptions{BaseRouter: r})
+	})
+
+	return router
+}
+
+func registerTestUser(t *testing.T, router http.Handler) openapi.UserToken {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodPost, "/users", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusCreated, rr.Code)
+
+	var token openapi.UserToken
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&token))
+	return token
+}
+
+func doAuthedRequest(t *testing.T, router http.Handler, method, path, token string, body []byte, extraHeaders map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	var bodyReader *bytes.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	} else {
+		bodyReader = bytes.NewReader(nil)
+	}
+	req, _ := http.NewRequest(method, path, bodyReader)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for k, v := range extraHeaders {
+		req.Header.Set(k, v)
+	}
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestAuthOwnership(t *testing.T) {
+	t.Run("unauthenticated request is rejected with 401", func(t *testing.T) {
+		db := setupHandlerTestDB(t)
+		defer db.Close()
+		router := setupAuthTestRouter(db)
+
+		rr := doAuthedRequest(t, router, http.MethodGet, "/items", "", nil, nil)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("each user only sees their own items in GET /items", func(t *testing.T) {
+		db := setupHandlerTestDB(t)
+		defer db.Close()
+		router := setupAuthTestRouter(db)
+
+		userA := registerTestUser(t, router)
+		userB := registerTestUser(t, router)
+
+		createRR := doAuthedRequest(t, router, http.MethodPost, "/items", userA.Token,
+			mustMarshal(t, openapi.NewItem{Name: "A's Item", Priority: 1}), nil)
+		require.Equal(t, http.StatusCreated, createRR.Code)
+
+		doAuthedRequest(t, router, http.MethodPost, "/items", userB.Token,
+			mustMarshal(t, openapi.NewItem{Name: "B's Item", Priority: 1}), nil)
+
+		listRR := doAuthedRequest(t, router, http.MethodGet, "/items", userA.Token, nil, nil)
+		require.Equal(t, http.StatusOK, listRR.Code)
+		var list openapi.ItemList
+		require.NoError(t, json.NewDecoder(listRR.Body).Decode(&list))
+		require.Len(t, list.Items, 1)
+		assert.Equal(t, "A's Item", list.Items[0].Name)
+	})
+
+	t.Run("user A gets 404 fetching, updating, or deleting user B's item", func(t *testing.T) {
+		db := setupHandlerTestDB(t)
+		defer db.Close()
+		router := setupAuthTestRouter(db)
+
+		userA := registerTestUser(t, router)
+		userB := registerTestUser(t, router)
+
+		createRR := doAuthedRequest(t, router, http.MethodPost, "/items", userB.Token,
+			mustMarshal(t, openapi.NewItem{Name: "B's Item", Priority: 1}), nil)
+		require.Equal(t, http.StatusCreated, createRR.Code)
+		var created openapi.Item
+		require.NoError(t, json.NewDecoder(createRR.Body).Decode(&created))
+		itemPath := fmt.Sprintf("/items/%d", *created.Id)
+
+		getRR := doAuthedRequest(t, router, http.MethodGet, itemPath, userA.Token, nil, nil)
+		assert.Equal(t, http.StatusNotFound, getRR.Code, "another user's item must look not-found")
+
+		updateRR := doAuthedRequest(t, router, http.MethodPut, itemPath, userA.Token,
+			mustMarshal(t, openapi.UpdateItem{Name: "Hijacked", Priority: 1}),
+			map[string]string{"If-Match": createRR.Header().Get("ETag")})
+		assert.Equal(t, http.StatusNotFound, updateRR.Code, "another user's item must look not-found, not merely forbidden, even with a well-formed If-Match")
+
+		deleteRR := doAuthedRequest(t, router, http.MethodDelete, itemPath, userA.Token, nil,
+			map[string]string{"If-Match": createRR.Header().Get("ETag")})
+		assert.Equal(t, http.StatusNotFound, deleteRR.Code)
+	})
+}
+
+func mustMarshal(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	body, err := json.Marshal(v)
+	require.NoError(t, err)
+	return body
+}
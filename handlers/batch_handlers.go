@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"app/apperrors"
+	"app/database"
+	"app/internal/generated/openapi"
+	"app/models"
+)
+
+// maxBatchOperations caps the number of operations accepted by BatchItems in
+// a single request; larger batches are rejected with 413 rather than tying
+// up one transaction for an unbounded amount of work.
+const maxBatchOperations = 500
+
+// errAbortedBatch is returned by BatchItems' WithTx callback to signal "the
+// atomic batch intentionally rolled back partway through, and results[] is
+// already fully populated to reflect that" - as opposed to a genuine error
+// from BeginTx/Commit, which should produce a 500.
+var errAbortedBatch = errors.New("batch: atomic batch aborted")
+
+// BatchItems implements the logic for the (POST /items:batch) endpoint. The
+// whole batch runs inside a single sql.Tx: with Atomic (the default), any
+// failed operation rolls the transaction back, that operation keeps its own
+// error result, and every other result is marked aborted (424); with
+// Atomic=false, each operation runs in its own savepoint so an
+// independent failure only affects that operation's result.
+func (s *ItemAPIServer) BatchItems(w http.ResponseWriter, r *http.Request) {
+	codec := negotiate(w, r)
+	if codec == nil {
+		return
+	}
+
+	ownerUserID, ok := s.authorizedOwner(w, r)
+	if !ok {
+		return
+	}
+
+	var req openapi.BatchRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	if len(req.Operations) > maxBatchOperations {
+		apperrors.Write(w, r, apperrors.RequestEntityTooLarge("batch.too_large",
+			fmt.Sprintf("batch accepts at most %d operations", maxBatchOperations)))
+		return
+	}
+
+	atomic := true
+	if req.Atomic != nil {
+		atomic = *req.Atomic
+	}
+
+	results := make([]openapi.BatchResult, len(req.Operations))
+
+	err := database.WithTx(r.Context(), s.DB, nil, func(ctx context.Context, tx *sql.Tx, conn database.DBTX) error {
+		aborted := false
+
+		for i, op := range req.Operations {
+			if aborted {
+				results[i] = abortedResult(i, "a prior operation in this atomic batch failed")
+				continue
+			}
+
+			savepoint := fmt.Sprintf("batch_op_%d", i)
+			if !atomic {
+				if _, err := tx.ExecContext(ctx, "SAVEPOINT "+savepoint); err != nil {
+					results[i] = errResult(i, http.StatusInternalServerError, "failed to start savepoint")
+					continue
+				}
+			}
+
+			result, opErr := applyBatchOperation(ctx, conn, i, op, ownerUserID, s.AuthDisabled)
+			results[i] = result
+
+			if opErr == nil {
+				if !atomic {
+					_, _ = tx.ExecContext(ctx, "RELEASE SAVEPOINT "+savepoint)
+				}
+				continue
+			}
+
+			if atomic {
+				aborted = true
+				for j := 0; j < i; j++ {
+					results[j] = abortedResult(j, "a later operation in this atomic batch failed")
+				}
+				continue
+			}
+			_, _ = tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+savepoint)
+		}
+
+		if aborted {
+			return errAbortedBatch
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errAbortedBatch) {
+		apperrors.Write(w, r, apperrors.Internal("batch.transaction_failed"))
+		return
+	}
+
+	writeBody(w, codec, http.StatusMultiStatus, openapi.BatchResponse{Results: results})
+}
+
+// applyBatchOperation runs a single batch operation against conn, returning
+// a result to surface to the client and, separately, a non-nil error
+// whenever that result represents a failure (so the caller can drive
+// rollback logic without re-inspecting the result's status code).
+func applyBatchOperation(ctx context.Context, conn database.DBTX, index int, op openapi.BatchOperation, ownerUserID int64, authDisabled bool) (openapi.BatchResult, error) {
+	switch op.Op {
+	case "create":
+		return applyCreateOp(ctx, conn, index, op, ownerUserID)
+	case "update":
+		return applyUpdateOp(ctx, conn, index, op, ownerUserID, authDisabled)
+	case "delete":
+		return applyDeleteOp(ctx, conn, index, op, ownerUserID, authDisabled)
+	default:
+		err := fmt.Errorf("unknown op %q", op.Op)
+		return errResult(index, http.StatusBadRequest, err.Error()), err
+	}
+}
+
+func applyCreateOp(ctx context.Context, conn database.DBTX, index int, op openapi.BatchOperation, ownerUserID int64) (openapi.BatchResult, error) {
+	if op.Item == nil {
+		err := errors.New("create requires item")
+		return errResult(index, http.StatusBadRequest, err.Error()), err
+	}
+	if violations := validateNewItem(*op.Item); len(violations) > 0 {
+		err := errors.New("validation failed")
+		return validationResult(index, violations), err
+	}
+
+	dbItem := models.Item{Name: op.Item.Name, Priority: int(op.Item.Priority), OwnerUserID: ownerUserID}
+	if op.Item.Description != nil {
+		dbItem.Description = *op.Item.Description
+	}
+
+	id, err := database.CreateItem(ctx, conn, dbItem)
+	if err != nil {
+		return errResult(index, http.StatusInternalServerError, "create failed"), err
+	}
+
+	version := int64(1)
+	apiItem := openapi.Item{
+		Id:          &id,
+		Name:        dbItem.Name,
+		Description: &dbItem.Description,
+		Priority:    int32(dbItem.Priority),
+		Version:     &version,
+	}
+	return openapi.BatchResult{Index: index, Status: http.StatusCreated, Item: &apiItem}, nil
+}
+
+func applyUpdateOp(ctx context.Context, conn database.DBTX, index int, op openapi.BatchOperation, ownerUserID int64, authDisabled bool) (openapi.BatchResult, error) {
+	if op.Id == nil || op.Item == nil {
+		err := errors.New("update requires id and item")
+		return errResult(index, http.StatusBadRequest, err.Error()), err
+	}
+	if violations := validateNewItem(*op.Item); len(violations) > 0 {
+		err := errors.New("validation failed")
+		return validationResult(index, violations), err
+	}
+
+	current, err := database.GetItem(ctx, conn, *op.Id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errResult(index, http.StatusNotFound, "item not found"), err
+		}
+		return errResult(index, http.StatusInternalServerError, "update failed"), err
+	}
+	if !authDisabled && current.OwnerUserID != ownerUserID {
+		err := errors.New("forbidden")
+		return errResult(index, http.StatusForbidden, "you do not own this item"), err
+	}
+
+	dbItem := models.Item{ID: *op.Id, Name: op.Item.Name, Priority: int(op.Item.Priority)}
+	if op.Item.Description != nil {
+		dbItem.Description = *op.Item.Description
+	}
+
+	if _, err := database.UpdateItem(ctx, conn, *op.Id, dbItem, current.Version); err != nil {
+		if errors.Is(err, database.ErrVersionMismatch) {
+			return errResult(index, http.StatusPreconditionFailed, "item was modified concurrently"), err
+		}
+		return errResult(index, http.StatusInternalServerError, "update failed"), err
+	}
+
+	updated, err := database.GetItem(ctx, conn, *op.Id)
+	if err != nil {
+		return errResult(index, http.StatusInternalServerError, "update failed"), err
+	}
+	apiItem := openapi.Item{
+		Id:          &updated.ID,
+		Name:        updated.Name,
+		Description: &updated.Description,
+		Priority:    int32(updated.Priority),
+		Version:     &updated.Version,
+	}
+	return openapi.BatchResult{Index: index, Status: http.StatusOK, Item: &apiItem}, nil
+}
+
+func applyDeleteOp(ctx context.Context, conn database.DBTX, index int, op openapi.BatchOperation, ownerUserID int64, authDisabled bool) (openapi.BatchResult, error) {
+	if op.Id == nil {
+		err := errors.New("delete requires id")
+		return errResult(index, http.StatusBadRequest, err.Error()), err
+	}
+
+	current, err := database.GetItem(ctx, conn, *op.Id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errResult(index, http.StatusNotFound, "item not found"), err
+		}
+		return errResult(index, http.StatusInternalServerError, "delete failed"), err
+	}
+	if !authDisabled && current.OwnerUserID != ownerUserID {
+		err := errors.New("forbidden")
+		return errResult(index, http.StatusForbidden, "you do not own this item"), err
+	}
+
+	if _, err := database.DeleteItem(ctx, conn, *op.Id, current.Version); err != nil {
+		if errors.Is(err, database.ErrVersionMismatch) {
+			return errResult(index, http.StatusPreconditionFailed, "item was modified concurrently"), err
+		}
+		return errResult(index, http.StatusInternalServerError, "delete failed"), err
+	}
+
+	return openapi.BatchResult{Index: index, Status: http.StatusNoContent}, nil
+}
+
+func errResult(index int, status int, message string) openapi.BatchResult {
+	return openapi.BatchResult{Index: index, Status: status, Error: &message}
+}
+
+func abortedResult(index int, message string) openapi.BatchResult {
+	message = "aborted: " + message
+	return openapi.BatchResult{Index: index, Status: http.StatusFailedDependency, Error: &message}
+}
+
+func validationResult(index int, violations []apperrors.FieldError) openapi.BatchResult {
+	message := violations[0].Field + ": " + violations[0].Reason
+	return errResult(index, http.StatusUnprocessableEntity, message)
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"app/apperrors"
+	"app/database"
+	"app/internal/generated/openapi"
+	"app/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func doBatch(t *testing.T, router http.Handler, req openapi.BatchRequest) (*httptest.ResponseRecorder, openapi.BatchResponse) {
+	t.Helper()
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+
+	httpReq, _ := http.NewRequest(http.MethodPost, "/items:batch", bytes.NewReader(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httpReq)
+
+	var resp openapi.BatchResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	return rr, resp
+}
+
+func TestBatchItemsOpenAPI(t *testing.T) {
+	t.Run("all operations succeed", func(t *testing.T) {
+		db := setupHandlerTestDB(t)
+		defer db.Close()
+		router := setupTestRouter(db)
+		existing := createTestItemDirectly(t, db, models.Item{Name: "Existing", Priority: 1})
+
+		rr, resp := doBatch(t, router, openapi.BatchRequest{
+			Operations: []openapi.BatchOperation{
+				{Op: "create", Item: &openapi.NewItem{Name: "Created", Priority: 2}},
+				{Op: "update", Id: &existing.ID, Item: &openapi.NewItem{Name: "Renamed", Priority: 3}},
+			},
+		})
+
+		require.Equal(t, http.StatusMultiStatus, rr.Code)
+		require.Len(t, resp.Results, 2)
+		assert.Equal(t, http.StatusCreated, resp.Results[0].Status)
+		assert.Equal(t, http.StatusOK, resp.Results[1].Status)
+		require.NotNil(t, resp.Results[1].Item)
+		assert.Equal(t, "Renamed", resp.Results[1].Item.Name)
+	})
+
+	t.Run("validation failure reported per-operation", func(t *testing.T) {
+		db := setupHandlerTestDB(t)
+		defer db.Close()
+		router := setupTestRouter(db)
+
+		rr, resp := doBatch(t, router, openapi.BatchRequest{
+			Operations: []openapi.BatchOperation{
+				{Op: "create", Item: &openapi.NewItem{Name: "", Priority: 1}},
+			},
+		})
+
+		require.Equal(t, http.StatusMultiStatus, rr.Code)
+		require.Len(t, resp.Results, 1)
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.Results[0].Status)
+		require.NotNil(t, resp.Results[0].Error)
+	})
+
+	t.Run("atomic batch rolls back every operation on a single failure", func(t *testing.T) {
+		db := setupHandlerTestDB(t)
+		defer db.Close()
+		router := setupTestRouter(db)
+
+		nonExistentID := int64(99999)
+		rr, resp := doBatch(t, router, openapi.BatchRequest{
+			Operations: []openapi.BatchOperation{
+				{Op: "create", Item: &openapi.NewItem{Name: "Should Roll Back", Priority: 1}},
+				{Op: "update", Id: &nonExistentID, Item: &openapi.NewItem{Name: "Nope", Priority: 1}},
+			},
+		})
+
+		require.Equal(t, http.StatusMultiStatus, rr.Code)
+		require.Len(t, resp.Results, 2)
+		assert.Equal(t, http.StatusFailedDependency, resp.Results[0].Status, "earlier successful op should be reported as aborted")
+		assert.Equal(t, http.StatusNotFound, resp.Results[1].Status)
+
+		listRR := httptest.NewRecorder()
+		listReq, _ := http.NewRequest(http.MethodGet, "/items", nil)
+		router.ServeHTTP(listRR, listReq)
+		var list openapi.ItemList
+		require.NoError(t, json.NewDecoder(listRR.Body).Decode(&list))
+		for _, item := range list.Items {
+			assert.NotEqual(t, "Should Roll Back", item.Name, "rolled-back create must not be visible")
+		}
+	})
+
+	t.Run("non-atomic batch isolates a failure to its own operation", func(t *testing.T) {
+		db := setupHandlerTestDB(t)
+		defer db.Close()
+		router := setupTestRouter(db)
+
+		nonExistentID := int64(99999)
+		atomic := false
+		rr, resp := doBatch(t, router, openapi.BatchRequest{
+			Atomic: &atomic,
+			Operations: []openapi.BatchOperation{
+				{Op: "create", Item: &openapi.NewItem{Name: "Survives", Priority: 1}},
+				{Op: "update", Id: &nonExistentID, Item: &openapi.NewItem{Name: "Nope", Priority: 1}},
+			},
+		})
+
+		require.Equal(t, http.StatusMultiStatus, rr.Code)
+		require.Len(t, resp.Results, 2)
+		assert.Equal(t, http.StatusCreated, resp.Results[0].Status, "sibling failure must not affect an independent op")
+		assert.Equal(t, http.StatusNotFound, resp.Results[1].Status)
+
+		listRR := httptest.NewRecorder()
+		listReq, _ := http.NewRequest(http.MethodGet, "/items", nil)
+		router.ServeHTTP(listRR, listReq)
+		var list openapi.ItemList
+		require.NoError(t, json.NewDecoder(listRR.Body).Decode(&list))
+		found := false
+		for _, item := range list.Items {
+			if item.Name == "Survives" {
+				found = true
+			}
+		}
+		assert.True(t, found, "the independent successful create must be committed")
+	})
+
+	t.Run("batch larger than the max operation count is rejected", func(t *testing.T) {
+		db := setupHandlerTestDB(t)
+		defer db.Close()
+		router := setupTestRouter(db)
+
+		ops := make([]openapi.BatchOperation, maxBatchOperations+1)
+		for i := range ops {
+			ops[i] = openapi.BatchOperation{Op: "create", Item: &openapi.NewItem{Name: "Item", Priority: 1}}
+		}
+		body, err := json.Marshal(openapi.BatchRequest{Operations: ops})
+		require.NoError(t, err)
+
+		req, _ := http.NewRequest(http.MethodPost, "/items:batch", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+		var problem apperrors.Problem
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&problem))
+		assert.Equal(t, "batch.too_large", problem.Code)
+	})
+
+	t.Run("concurrent batches serialize writes without corrupting versions", func(t *testing.T) {
+		db := setupHandlerTestDB(t)
+		defer db.Close()
+		router := setupTestRouter(db)
+		existing := createTestItemDirectly(t, db, models.Item{Name: "Shared", Priority: 1})
+
+		const concurrentBatches = 10
+		var wg sync.WaitGroup
+		wg.Add(concurrentBatches)
+		for i := 0; i < concurrentBatches; i++ {
+			go func(i int) {
+				defer wg.Done()
+				doBatch(t, router, openapi.BatchRequest{
+					Operations: []openapi.BatchOperation{
+						{Op: "create", Item: &openapi.NewItem{Name: "Concurrent", Priority: int32(i + 1)}},
+					},
+				})
+			}(i)
+		}
+		wg.Wait()
+
+		listReq, _ := http.NewRequest(http.MethodGet, "/items?limit=100", nil)
+		listRR := httptest.NewRecorder()
+		router.ServeHTTP(listRR, listReq)
+		var list openapi.ItemList
+		require.NoError(t, json.NewDecoder(listRR.Body).Decode(&list))
+
+		created := 0
+		for _, item := range list.Items {
+			if item.Name == "Concurrent" {
+				created++
+			}
+		}
+		assert.Equal(t, concurrentBatches, created, "every concurrent batch's create should have been committed exactly once")
+
+		unchanged, err := database.GetItem(context.Background(), db, existing.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), unchanged.Version, "an item untouched by any batch should keep its original version")
+	})
+}
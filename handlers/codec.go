@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"app/apperrors"
+	"app/internal/generated/itempb"
+	"app/internal/generated/openapi"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Codec converts between Go values and one wire format, following the same
+// multi-format-serving pattern as the Kubernetes apiserver.
+type Codec interface {
+	// Marshal encodes v, returning the bytes and the Content-Type to send
+	// them with.
+	Marshal(v any) ([]byte, string, error)
+	Unmarshal(data []byte, v any) error
+	ContentType() string
+}
+
+const (
+	mimeJSON     = "application/json"
+	mimeProtobuf = "application/x-protobuf"
+	mimeMsgpack  = "application/msgpack"
+)
+
+var codecs = map[string]Codec{
+	mimeJSON:     jsonCodec{},
+	mimeProtobuf: protobufCodec{},
+	mimeMsgpack:  msgpackCodec{},
+}
+
+// jsonCodec is the default, always-available codec.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, string, error) {
+	b, err := json.Marshal(v)
+	return b, mimeJSON, err
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) ContentType() string { return mimeJSON }
+
+// protobufCodec serves openapi.Item and friends as application/x-protobuf by
+// converting to/from the generated itempb messages.
+type protobufCodec struct{}
+
+func (protobufCodec) Marshal(v any) ([]byte, string, error) {
+	msg, err := toProtoMessage(v)
+	if err != nil {
+		return nil, "", err
+	}
+	b, err := proto.Marshal(msg)
+	return b, mimeProtobuf, err
+}
+
+func (protobufCodec) Unmarshal(data []byte, v any) error {
+	switch dst := v.(type) {
+	case *openapi.NewItem:
+		var msg itempb.NewItem
+		if err := proto.Unmarshal(data, &msg); err != nil {
+			return err
+		}
+		*dst = newItemFromProto(&msg)
+		return nil
+	case *openapi.UpdateItem:
+		var msg itempb.UpdateItem
+		if err := proto.Unmarshal(data, &msg); err != nil {
+			return err
+		}
+		*dst = updateItemFromProto(&msg)
+		return nil
+	default:
+		return fmt.Errorf("codec: %T has no protobuf mapping", v)
+	}
+}
+
+func (protobufCodec) ContentType() string { return mimeProtobuf }
+
+// msgpackCodec serves openapi.Item and friends as application/msgpack
+// directly off their msgpack struct tags, no conversion required.
+type msgpackCodec struct{}
+
+func (msgpackCodec) Marshal(v any) ([]byte, string, error) {
+	b, err := msgpack.Marshal(v)
+	return b, mimeMsgpack, err
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v any) error {
+	return msgpack.Unmarshal(data, v)
+}
+
+func (msgpackCodec) ContentType() string { return mimeMsgpack }
+
+// toProtoMessage maps a response value to its itempb equivalent.
+func toProtoMessage(v any) (proto.Message, error) {
+	switch val := v.(type) {
+	case openapi.Item:
+		return itemToProto(val), nil
+	case openapi.ItemList:
+		return itemListToProto(val), nil
+	default:
+		return nil, fmt.Errorf("codec: %T has no protobuf mapping", v)
+	}
+}
+
+func itemToProto(it openapi.Item) *itempb.Item {
+	msg := &itempb.Item{Name: it.Name, Priority: it.Priority}
+	if it.Id != nil {
+		msg.Id = *it.Id
+	}
+	if it.Description != nil {
+		msg.Description = *it.Description
+	}
+	if it.Version != nil {
+		msg.Version = *it.Version
+	}
+	return msg
+}
+
+func itemListToProto(list openapi.ItemList) *itempb.ItemList {
+	msg := &itempb.ItemList{HasMore: list.HasMore}
+	if list.NextCursor != nil {
+		msg.NextCursor = *list.NextCursor
+	}
+	for _, it := range list.Items {
+		msg.Items = append(msg.Items, itemToProto(it))
+	}
+	return msg
+}
+
+func newItemFromProto(msg *itempb.NewItem) openapi.NewItem {
+	item := openapi.NewItem{Name: msg.Name, Priority: msg.Priority}
+	if msg.Description != "" {
+		item.Description = &msg.Description
+	}
+	return item
+}
+
+func updateItemFromProto(msg *itempb.UpdateItem) openapi.UpdateItem {
+	item := openapi.UpdateItem{Name: msg.Name, Priority: msg.Priority}
+	if msg.Description != "" {
+		item.Description = &msg.Description
+	}
+	return item
+}
+
+// negotiate picks the Codec to use for a response from the request's Accept
+// header (falling back to JSON for an empty or wildcard header), writing a
+// 406 Problem and returning nil if none of the requested media types are
+// available.
+func negotiate(w http.ResponseWriter, r *http.Request) Codec {
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return jsonCodec{}
+	}
+	for _, part := range strings.Split(accept, ",") {
+		mediaType := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		if mediaType == "*/*" {
+			return jsonCodec{}
+		}
+		if codec, ok := codecs[mediaType]; ok {
+			return codec
+		}
+	}
+
+	apperrors.Write(w, r, apperrors.NotAcceptable("item.not_acceptable", fmt.Sprintf("none of the requested media types (%s) are available", accept)))
+	return nil
+}
+
+// decodeBody picks the Codec for a request's Content-Type and unmarshals its
+// body into dst, writing a 415 Problem and returning false if the
+// Content-Type isn't one this server understands.
+func decodeBody(w http.ResponseWriter, r *http.Request, dst any) bool {
+	codec := jsonCodec{}.ContentType()
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		codec = strings.TrimSpace(strings.SplitN(ct, ";", 2)[0])
+	}
+	c, ok := codecs[codec]
+	if !ok {
+		apperrors.Write(w, r, apperrors.UnsupportedMediaType("item.unsupported_content_type", fmt.Sprintf("unsupported Content-Type %q", codec)))
+		return false
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		apperrors.Write(w, r, apperrors.BadRequest("item.invalid_payload", "failed to read request body"))
+		return false
+	}
+	defer r.Body.Close()
+
+	if err := c.Unmarshal(body, dst); err != nil {
+		apperrors.Write(w, r, apperrors.BadRequest("item.invalid_payload", "invalid request payload: "+err.Error()))
+		return false
+	}
+	return true
+}
+
+// writeBody encodes v with codec and writes it as the response body with the
+// given status, setting Content-Type to the codec's format.
+func writeBody(w http.ResponseWriter, codec Codec, status int, v any) {
+	b, contentType, err := codec.Marshal(v)
+	if err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	_, _ = w.Write(b)
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"fmt"
+	"testing"
+
+	"app/internal/generated/openapi"
+)
+
+// buildBenchItemList constructs a GetItems-shaped response of n items for the
+// codec benchmarks below.
+func buildBenchItemList(n int) openapi.ItemList {
+	items := make([]openapi.Item, n)
+	for i := range items {
+		id := int64(i)
+		version := int64(1)
+		desc := "a representative item description for payload-size comparisons"
+		items[i] = openapi.Item{
+			Id:          &id,
+			Name:        fmt.Sprintf("Item %d", i),
+			Description: &desc,
+			Priority:    int32(i % 10),
+			Version:     &version,
+		}
+	}
+	return openapi.ItemList{Items: items, HasMore: false}
+}
+
+// BenchmarkGetItemsCodecs compares the JSON, protobuf, and msgpack codecs'
+// CPU cost and wire size when encoding a 10k-row GetItems response, the
+// shape this negotiation was added for.
+func BenchmarkGetItemsCodecs(b *testing.B) {
+	list := buildBenchItemList(10000)
+
+	codecsUnderTest := []struct {
+		name  string
+		codec Codec
+	}{
+		{"JSON", jsonCodec{}},
+		{"Protobuf", protobufCodec{}},
+		{"Msgpack", msgpackCodec{}},
+	}
+
+	for _, c := range codecsUnderTest {
+		c := c
+		b.Run(c.name, func(b *testing.B) {
+			payload, _, err := c.codec.Marshal(list)
+			if err != nil {
+				b.Fatalf("Marshal: %v", err)
+			}
+			b.ReportMetric(float64(len(payload)), "bytes/payload")
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, _, err := c.codec.Marshal(list); err != nil {
+					b.Fatalf("Marshal: %v", err)
+				}
+			}
+		})
+	}
+}
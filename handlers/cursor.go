@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"app/database"
+)
+
+// cursorPayload is the JSON shape base64-encoded into the opaque "cursor"
+// query parameter and "next_cursor" response field. Callers must treat it as
+// opaque; the fields present depend on which column GetItems is sorted by.
+type cursorPayload struct {
+	LastPriority int    `json:"last_priority,omitempty"`
+	LastName     string `json:"last_name,omitempty"`
+	LastID       int64  `json:"last_id"`
+}
+
+// encodeCursor renders a keyset position as the opaque string handed back to
+// clients in next_cursor.
+func encodeCursor(c database.ItemCursor) string {
+	payload := cursorPayload{LastPriority: c.LastPriority, LastName: c.LastName, LastID: c.LastID}
+	raw, _ := json.Marshal(payload) // payload is always marshalable
+	return base64.URLEncoding.EncodeToString(raw)
+}
+
+// decodeCursor reverses encodeCursor, rejecting anything that isn't a cursor
+// this server produced.
+func decodeCursor(raw string) (database.ItemCursor, error) {
+	data, err := base64.URLEncoding.DecodeString(raw)
+	if err != nil {
+		return database.ItemCursor{}, err
+	}
+	var payload cursorPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return database.ItemCursor{}, err
+	}
+	return database.ItemCursor{
+		LastPriority: payload.LastPriority,
+		LastName:     payload.LastName,
+		LastID:       payload.LastID,
+	}, nil
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"app/database"
+	"app/internal/generated/openapi"
+	"app/models"
+)
+
+// webhookWorkerCount is the size of the Dispatcher's delivery goroutine pool.
+const webhookWorkerCount = 4
+
+// webhookEventBody is the payload POSTed to each subscriber.
+type webhookEventBody struct {
+	Event     string       `json:"event"`
+	Item      openapi.Item `json:"item"`
+	Timestamp string       `json:"timestamp"`
+}
+
+type dispatchJob struct {
+	sub     models.WebhookSubscription
+	event   string
+	payload []byte
+}
+
+// Dispatcher delivers item lifecycle events to every subscribed webhook. It
+// runs a fixed pool of worker goroutines pulling off a shared job queue, so a
+// slow or unreachable receiver can't starve deliveries to the others, and
+// retries a failing delivery with exponential backoff before giving up and
+// recording it in the webhook_dead_letters table.
+type Dispatcher struct {
+	db         database.Store
+	httpClient *http.Client
+	backoffs   []time.Duration
+	jobs       chan dispatchJob
+}
+
+// NewDispatcher creates a Dispatcher and starts its worker pool.
+func NewDispatcher(db database.Store) *Dispatcher {
+	d := &Dispatcher{
+		db:         db,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		backoffs:   []time.Duration{time.Second, 5 * time.Second, 30 * time.Second},
+		jobs:       make(chan dispatchJob, 256),
+	}
+	for i := 0; i < webhookWorkerCount; i++ {
+		go d.worker()
+	}
+	return d
+}
+
+func (d *Dispatcher) worker() {
+	for job := range d.jobs {
+		d.deliver(job)
+	}
+}
+
+// Dispatch fans an item lifecycle event out to every subscriber whose Events
+// include it. It looks up subscriptions and enqueues deliveries in its own
+// goroutine so the HTTP handler that triggered the event is never blocked on
+// webhook delivery.
+func (d *Dispatcher) Dispatch(event string, item openapi.Item) {
+	go func() {
+		// context.Background(), not the triggering request's context: this
+		// goroutine is deliberately detached so it keeps delivering (and
+		// retrying) long after the request that triggered it has returned.
+		subs, err := database.GetWebhooks(context.Background(), d.db)
+		if err != nil {
+			return
+		}
+		payload, err := json.Marshal(webhookEventBody{
+			Event:     event,
+			Item:      item,
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+		})
+		if err != nil {
+			return
+		}
+		for _, sub := range subs {
+			if !subscribesTo(sub, event) {
+				continue
+			}
+			d.jobs <- dispatchJob{sub: sub, event: event, payload: payload}
+		}
+	}()
+}
+
+func subscribesTo(sub models.WebhookSubscription, event string) bool {
+	for _, e := range sub.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// deliver attempts a single job, retrying with the Dispatcher's backoff
+// schedule and recording a dead letter once that schedule is exhausted.
+func (d *Dispatcher) deliver(job dispatchJob) {
+	var lastErr error
+	for attempt := 0; ; attempt++ {
+		if lastErr = d.send(job.sub, job.payload); lastErr == nil {
+			return
+		}
+		if attempt >= len(d.backoffs) {
+			break
+		}
+		time.Sleep(d.backoffs[attempt])
+	}
+
+	_ = database.CreateWebhookDeadLetter(context.Background(), d.db, job.sub.ID, job.event, string(job.payload), lastErr.Error())
+}
+
+// send performs one delivery attempt, signing the payload with the
+// subscription's secret the same way GitHub/Stripe-style webhooks do.
+func (d *Dispatcher) send(sub models.WebhookSubscription, payload []byte) error {
+	req, err := http.NewRequest(http.MethodPost, sub.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Signature", "sha256="+signPayload(sub.Secret, payload))
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook receiver returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func signPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// itemETag renders an item's version as a weak ETag. Weak because the
+// comparison we care about is "same version", not byte-identical
+// representation.
+func itemETag(version int64) string {
+	return fmt.Sprintf(`W/"%d"`, version)
+}
+
+// parseItemETag extracts the version encoded by itemETag, accepting both the
+// weak (W/"1") and plain ("1") forms a client might send back in If-Match /
+// If-None-Match.
+func parseItemETag(raw string) (int64, bool) {
+	raw = strings.TrimSpace(raw)
+	raw = strings.TrimPrefix(raw, "W/")
+	raw = strings.Trim(raw, `"`)
+	version, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return version, true
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"app/internal/generated/openapi"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func registerJWTUser(t *testing.T, router http.Handler, username, password string) authToken {
+	t.Helper()
+	rr := doAuthedRequest(t, router, http.MethodPost, "/auth/register", "",
+		mustMarshal(t, credentials{Username: username, Password: password}), nil)
+	require.Equal(t, http.StatusCreated, rr.Code)
+
+	var token authToken
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&token))
+	return token
+}
+
+func TestJWTAuthFlow(t *testing.T) {
+	t.Run("register then login issue tokens that authorize /items", func(t *testing.T) {
+		db := setupHandlerTestDB(t)
+		defer db.Close()
+		router := setupAuthTestRouter(db)
+
+		registerTok := registerJWTUser(t, router, "alice", "correct-horse")
+
+		createRR := doAuthedRequest(t, router, http.MethodPost, "/items", registerTok.Token,
+			mustMarshal(t, openapi.NewItem{Name: "Alice's Item", Priority: 1}), nil)
+		assert.Equal(t, http.StatusCreated, createRR.Code)
+
+		loginRR := doAuthedRequest(t, router, http.MethodPost, "/auth/login", "",
+			mustMarshal(t, credentials{Username: "alice", Password: "correct-horse"}), nil)
+		require.Equal(t, http.StatusOK, loginRR.Code)
+		var loginTok authToken
+		require.NoError(t, json.NewDecoder(loginRR.Body).Decode(&loginTok))
+
+		listRR := doAuthedRequest(t, router, http.MethodGet, "/items", loginTok.Token, nil, nil)
+		require.Equal(t, http.StatusOK, listRR.Code)
+		var list openapi.ItemList
+		require.NoError(t, json.NewDecoder(listRR.Body).Decode(&list))
+		require.Len(t, list.Items, 1)
+	})
+
+	t.Run("login with wrong password is rejected with 401", func(t *testing.T) {
+		db := setupHandlerTestDB(t)
+		defer db.Close()
+		router := setupAuthTestRouter(db)
+
+		registerJWTUser(t, router, "bob", "right-password")
+
+		rr := doAuthedRequest(t, router, http.MethodPost, "/auth/login", "",
+			mustMarshal(t, credentials{Username: "bob", Password: "wrong-password"}), nil)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("login with unknown username is rejected with 401", func(t *testing.T) {
+		db := setupHandlerTestDB(t)
+		defer db.Close()
+		router := setupAuthTestRouter(db)
+
+		rr := doAuthedRequest(t, router, http.MethodPost, "/auth/login", "",
+			mustMarshal(t, credentials{Username: "nobody", Password: "whatever1"}), nil)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("registering a duplicate username is rejected with 409", func(t *testing.T) {
+		db := setupHandlerTestDB(t)
+		defer db.Close()
+		router := setupAuthTestRouter(db)
+
+		registerJWTUser(t, router, "carol", "first-password")
+
+		rr := doAuthedRequest(t, router, http.MethodPost, "/auth/register", "",
+			mustMarshal(t, credentials{Username: "carol", Password: "second-password"}), nil)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("registering with a short password is rejected with 422", func(t *testing.T) {
+		db := setupHandlerTestDB(t)
+		defer db.Close()
+		router := setupAuthTestRouter(db)
+
+		rr := doAuthedRequest(t, router, http.MethodPost, "/auth/register", "",
+			mustMarshal(t, credentials{Username: "dave", Password: "short"}), nil)
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+}
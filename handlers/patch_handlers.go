@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"app/apperrors"
+	"app/database"
+	"app/internal/generated/openapi"
+	"app/models"
+)
+
+const (
+	mimeMergePatch = "application/merge-patch+json"
+	mimeJSONPatch  = "application/json-patch+json"
+)
+
+// jsonPatchOp is one operation in an RFC 6902 JSON Patch document.
+type jsonPatchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// PatchItemById implements the logic for the (PATCH /items/{id}) endpoint.
+// Like UpdateItemById it requires a valid If-Match header; unlike
+// UpdateItemById it only changes the fields the patch document names,
+// dispatching on Content-Type to either a JSON Merge Patch (RFC 7396) or a
+// JSON Patch (RFC 6902) applier before re-validating and persisting.
+func (s *ItemAPIServer) PatchItemById(w http.ResponseWriter, r *http.Request, id int64) {
+	codec := negotiate(w, r)
+	if codec == nil {
+		return
+	}
+
+	ownerUserID, ok := s.authorizedOwner(w, r)
+	if !ok {
+		return
+	}
+
+	expectedVersion, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
+	current, err := database.GetItem(r.Context(), s.DB, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			apperrors.Write(w, r, apperrors.NotFound("item.not_found", "no item with the given id exists to patch"))
+		} else {
+			apperrors.Write(w, r, apperrors.Internal("item.get_failed"))
+		}
+		return
+	}
+	if !s.AuthDisabled && current.OwnerUserID != ownerUserID {
+		// Indistinguishable from not-found, so a probe for another user's
+		// item id (with any syntactically valid If-Match) can't be used to
+		// confirm it exists - see GetItemById and checkOwnership in
+		// openapi_handlers.go.
+		apperrors.Write(w, r, apperrors.NotFound("item.not_found", "no item with the given id exists to patch"))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		apperrors.Write(w, r, apperrors.BadRequest("item.invalid_payload", "failed to read request body"))
+		return
+	}
+	defer r.Body.Close()
+
+	patched := current
+	contentType := strings.TrimSpace(strings.SplitN(r.Header.Get("Content-Type"), ";", 2)[0])
+	switch contentType {
+	case mimeMergePatch:
+		if !applyMergePatch(w, r, body, &patched) {
+			return
+		}
+	case mimeJSONPatch:
+		if !applyJSONPatch(w, r, body, &patched) {
+			return
+		}
+	default:
+		apperrors.Write(w, r, apperrors.UnsupportedMediaType("item.unsupported_content_type", fmt.Sprintf("unsupported Content-Type %q for patch", contentType)))
+		return
+	}
+
+	if violations := validatePatchedItem(patched); len(violations) > 0 {
+		apperrors.Write(w, r, apperrors.Validation("item.validation_failed", violations))
+		return
+	}
+
+	rowsAffected, err := database.UpdateItem(r.Context(), s.DB, id, patched, expectedVersion)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			apperrors.Write(w, r, apperrors.NotFound("item.not_found", "no item with the given id exists to patch"))
+		case errors.Is(err, database.ErrVersionMismatch):
+			apperrors.Write(w, r, apperrors.PreconditionFailed("item.version_mismatch", "the item was modified since it was last fetched"))
+		default:
+			apperrors.Write(w, r, apperrors.Internal("item.patch_failed"))
+		}
+		return
+	}
+	if rowsAffected == 0 {
+		apperrors.Write(w, r, apperrors.NotFound("item.not_found", "no item with the given id exists to patch"))
+		return
+	}
+
+	updatedDbItem, err := database.GetItem(r.Context(), s.DB, id)
+	if err != nil {
+		apperrors.Write(w, r, apperrors.Internal("item.patch_confirm_failed"))
+		return
+	}
+
+	responseItem := openapi.Item{
+		Id:          &updatedDbItem.ID,
+		Name:        updatedDbItem.Name,
+		Description: &updatedDbItem.Description,
+		Priority:    int32(updatedDbItem.Priority),
+		Version:     &updatedDbItem.Version,
+	}
+
+	w.Header().Set("ETag", itemETag(updatedDbItem.Version))
+	writeBody(w, codec, http.StatusOK, responseItem)
+}
+
+// applyMergePatch merges a JSON Merge Patch (RFC 7396) document onto item.
+// A JSON null for a field resets it to its zero value (the closest
+// equivalent this flat, non-pointer model has to "removed"); a field absent
+// from the document is left untouched.
+func applyMergePatch(w http.ResponseWriter, r *http.Request, body []byte, item *models.Item) bool {
+	var patch map[string]json.RawMessage
+	if err := json.Unmarshal(body, &patch); err != nil {
+		apperrors.Write(w, r, apperrors.BadRequest("item.invalid_payload", "invalid merge patch document: "+err.Error()))
+		return false
+	}
+
+	for field, raw := range patch {
+		isNull := string(raw) == "null"
+		switch field {
+		case "name":
+			if isNull {
+				item.Name = ""
+				continue
+			}
+			if err := json.Unmarshal(raw, &item.Name); err != nil {
+				apperrors.Write(w, r, apperrors.BadRequest("item.invalid_payload", "name must be a string"))
+				return false
+			}
+		case "description":
+			if isNull {
+				item.Description = ""
+				continue
+			}
+			if err := json.Unmarshal(raw, &item.Description); err != nil {
+				apperrors.Write(w, r, apperrors.BadRequest("item.invalid_payload", "description must be a string"))
+				return false
+			}
+		case "priority":
+			if isNull {
+				item.Priority = 0
+				continue
+			}
+			if err := json.Unmarshal(raw, &item.Priority); err != nil {
+				apperrors.Write(w, r, apperrors.BadRequest("item.invalid_payload", "priority must be an integer"))
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// applyJSONPatch applies an RFC 6902 JSON Patch document onto item,
+// supporting add, remove, replace, and test against the /name, /description,
+// and /priority paths (the only mutable fields this resource exposes).
+func applyJSONPatch(w http.ResponseWriter, r *http.Request, body []byte, item *models.Item) bool {
+	var ops []jsonPatchOp
+	if err := json.Unmarshal(body, &ops); err != nil {
+		apperrors.Write(w, r, apperrors.BadRequest("item.invalid_payload", "invalid JSON Patch document: "+err.Error()))
+		return false
+	}
+
+	for _, op := range ops {
+		if op.Path != "/name" && op.Path != "/description" && op.Path != "/priority" {
+			apperrors.Write(w, r, apperrors.BadRequest("item.invalid_patch_path", fmt.Sprintf("unknown patch path %q", op.Path)))
+			return false
+		}
+
+		switch op.Op {
+		case "test":
+			current, err := json.Marshal(currentPatchValue(item, op.Path))
+			if err != nil || string(current) != string(op.Value) {
+				apperrors.Write(w, r, apperrors.Conflict("item.patch_test_failed", fmt.Sprintf("test operation on %q did not match the current value", op.Path)))
+				return false
+			}
+		case "remove":
+			setPatchValue(item, op.Path, json.RawMessage("null"), true)
+		case "add", "replace":
+			if !setPatchValue(item, op.Path, op.Value, false) {
+				apperrors.Write(w, r, apperrors.BadRequest("item.invalid_payload", fmt.Sprintf("invalid value for %q", op.Path)))
+				return false
+			}
+		default:
+			apperrors.Write(w, r, apperrors.BadRequest("item.invalid_patch_op", fmt.Sprintf("unknown patch op %q", op.Op)))
+			return false
+		}
+	}
+	return true
+}
+
+// currentPatchValue reads the value at path out of item, for "test" ops.
+func currentPatchValue(item *models.Item, path string) any {
+	switch path {
+	case "/name":
+		return item.Name
+	case "/description":
+		return item.Description
+	case "/priority":
+		return item.Priority
+	default:
+		return nil
+	}
+}
+
+// setPatchValue writes value at path into item. If clear is true, the field
+// is reset to its zero value instead of being unmarshaled (used by "remove").
+func setPatchValue(item *models.Item, path string, value json.RawMessage, clear bool) bool {
+	switch path {
+	case "/name":
+		if clear {
+			item.Name = ""
+			return true
+		}
+		return json.Unmarshal(value, &item.Name) == nil
+	case "/description":
+		if clear {
+			item.Description = ""
+			return true
+		}
+		return json.Unmarshal(value, &item.Description) == nil
+	case "/priority":
+		if clear {
+			item.Priority = 0
+			return true
+		}
+		return json.Unmarshal(value, &item.Priority) == nil
+	default:
+		return false
+	}
+}
+
+// validatePatchedItem applies the same field rules as validateNewItem and
+// validateUpdateItem to a patch result, since a patch can produce an
+// otherwise-invalid item (e.g. clearing a required field).
+func validatePatchedItem(item models.Item) []apperrors.FieldError {
+	var violations []apperrors.FieldError
+	if item.Name == "" {
+		violations = append(violations, apperrors.FieldError{Field: "name", Reason: "must not be empty"})
+	}
+	if item.Priority <= 0 {
+		violations = append(violations, apperrors.FieldError{Field: "priority", Reason: "must be a positive integer"})
+	}
+	return violations
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"app/apperrors"
+	"app/database"
+	"app/internal/generated/openapi"
+)
+
+// UserAPIServer implements openapi.UserServerInterface. It is intentionally
+// unauthenticated: registering is how a caller obtains the bearer token
+// AuthMiddleware requires everywhere else.
+type UserAPIServer struct {
+	DB database.Store
+}
+
+var _ openapi.UserServerInterface = (*UserAPIServer)(nil)
+
+// NewUserAPIServer creates a new UserAPIServer.
+func NewUserAPIServer(db database.Store) *UserAPIServer {
+	return &UserAPIServer{DB: db}
+}
+
+// CreateUser implements the logic for the (POST /users) endpoint.
+func (s *UserAPIServer) CreateUser(w http.ResponseWriter, r *http.Request) {
+	user, err := database.CreateUser(r.Context(), s.DB)
+	if err != nil {
+		apperrors.Write(w, r, apperrors.Internal("user.create_failed"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(openapi.UserToken{UserId: user.ID, Token: user.Token})
+}
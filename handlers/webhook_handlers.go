@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"app/apperrors"
+	"app/database"
+	"app/internal/generated/openapi"
+	"app/models"
+)
+
+// WebhookAPIServer implements openapi.WebhookServerInterface, alongside
+// ItemAPIServer, which holds the Dispatcher that delivers the events these
+// subscriptions register for.
+type WebhookAPIServer struct {
+	DB database.Store
+}
+
+var _ openapi.WebhookServerInterface = (*WebhookAPIServer)(nil)
+
+// NewWebhookAPIServer creates a new WebhookAPIServer.
+func NewWebhookAPIServer(db database.Store) *WebhookAPIServer {
+	return &WebhookAPIServer{DB: db}
+}
+
+// CreateWebhook implements the logic for the (POST /webhooks) endpoint.
+func (s *WebhookAPIServer) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var requestBody openapi.NewWebhook
+	if !decodeBody(w, r, &requestBody) {
+		return
+	}
+
+	if violations := validateNewWebhook(requestBody); len(violations) > 0 {
+		apperrors.Write(w, r, apperrors.Validation("webhook.validation_failed", violations))
+		return
+	}
+
+	sub := models.WebhookSubscription{URL: requestBody.Url, Events: requestBody.Events, Secret: requestBody.Secret}
+	id, err := database.CreateWebhook(r.Context(), s.DB, sub)
+	if err != nil {
+		apperrors.Write(w, r, apperrors.Internal("webhook.create_failed"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(openapi.Webhook{Id: id, Url: sub.URL, Events: sub.Events})
+}
+
+// ListWebhooks implements the logic for the (GET /webhooks) endpoint.
+func (s *WebhookAPIServer) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	subs, err := database.GetWebhooks(r.Context(), s.DB)
+	if err != nil {
+		apperrors.Write(w, r, apperrors.Internal("webhook.list_failed"))
+		return
+	}
+
+	webhooks := make([]openapi.Webhook, len(subs))
+	for i, sub := range subs {
+		webhooks[i] = openapi.Webhook{
+			Id:        sub.ID,
+			Url:       sub.URL,
+			Events:    sub.Events,
+			CreatedAt: sub.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(webhooks)
+}
+
+// DeleteWebhookById implements the logic for the (DELETE /webhooks/{id}) endpoint.
+func (s *WebhookAPIServer) DeleteWebhookById(w http.ResponseWriter, r *http.Request, id int64) {
+	rowsAffected, err := database.DeleteWebhook(r.Context(), s.DB, id)
+	if err != nil {
+		apperrors.Write(w, r, apperrors.Internal("webhook.delete_failed"))
+		return
+	}
+	if rowsAffected == 0 {
+		apperrors.Write(w, r, apperrors.NotFound("webhook.not_found", "no webhook subscription with the given id exists"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// validateNewWebhook mirrors validateNewItem's collect-everything style for
+// the webhook subscription payload.
+func validateNewWebhook(req openapi.NewWebhook) []apperrors.FieldError {
+	var violations []apperrors.FieldError
+	if req.Url == "" {
+		violations = append(violations, apperrors.FieldError{Field: "url", Reason: "must not be empty"})
+	}
+	if len(req.Events) == 0 {
+		violations = append(violations, apperrors.FieldError{Field: "events", Reason: "must list at least one event"})
+	}
+	if req.Secret == "" {
+		violations = append(violations, apperrors.FieldError{Field: "secret", Reason: "must not be empty"})
+	}
+	return violations
+}
@@ -0,0 +1,49 @@
+// Package golden implements golden-file assertions for tests: Assert
+// compares a value against the contents of a checked-in fixture file,
+// failing the test on any difference. Run the package's tests with -update
+// to regenerate every golden file they exercise instead of comparing
+// against it.
+package golden
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files instead of comparing against them")
+
+// Assert marshals got as indented JSON and compares it against the contents
+// of path, failing t if they differ. With -update, it writes got to path
+// instead of comparing, so a changed golden can be regenerated with:
+//
+//	go test ./database -run TestName -update
+//
+// The -update flag is only defined in test binaries that import this
+// package, so passing it to `go test ./...` fails in the other packages.
+func Assert(t testing.TB, path string, got interface{}) {
+	t.Helper()
+
+	gotJSON, err := json.MarshalIndent(got, "", "  ")
+	if err != nil {
+		t.Fatalf("golden: marshaling comparison value: %v", err)
+	}
+	gotJSON = append(gotJSON, '\n')
+
+	if *update {
+		if err := os.WriteFile(path, gotJSON, 0o644); err != nil {
+			t.Fatalf("golden: updating %s: %v", path, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("golden: reading %s (run with -update to create it): %v", path, err)
+	}
+
+	if string(want) != string(gotJSON) {
+		t.Errorf("golden: %s does not match (run with -update to refresh it)\n--- want ---\n%s\n--- got ---\n%s", path, want, gotJSON)
+	}
+}
@@ -3,25 +3,38 @@ package main
 //go:generate go run github.com/oapi-codegen/oapi-codegen/v2/cmd/oapi-codegen -config oapi-codegen-config.yaml openapi.yaml
 
 import (
-	"database/sql"
+	"context"
 	"log"
 	"net/http"
+	"os"
 	"time"
 
+	"app/apperrors"
 	"app/database"
 	"app/handlers"
 	"app/internal/generated/openapi" // Added for generated code
+	appmiddleware "app/middleware"
 
 	"github.com/go-chi/chi/v5" // Replaced gorilla/mux
 	// chi_middleware "github.com/go-chi/chi/v5/middleware" // Optional: For Chi's own middlewares
-	_ "github.com/mattn/go-sqlite3"
 )
 
-var DB *sql.DB
+var DB database.Store
+
+// databaseDSN resolves which backend and connection string OpenStore should
+// use: DATABASE_URL, when set, is passed straight through (its scheme picks
+// the backend - see database.OpenStore), otherwise this falls back to the
+// same local SQLite file the server has always used.
+func databaseDSN() string {
+	if dsn := os.Getenv("DATABASE_URL"); dsn != "" {
+		return dsn
+	}
+	return "sqlite.db"
+}
 
 func init() {
 	var err error
-	DB, err = database.InitDB("sqlite.db") // Assuming InitDB is compatible or adapted
+	DB, err = database.OpenStore(databaseDSN())
 	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
@@ -38,59 +51,101 @@ func loggingMiddleware(next http.Handler) http.Handler {
 }
 
 func main() {
+	// Exports spans to OTEL_EXPORTER_OTLP_ENDPOINT when set; a no-op
+	// otherwise, so running without a collector nearby still works.
+	shutdownTracer, err := appmiddleware.InitTracer("restserver")
+	if err != nil {
+		log.Fatalf("Failed to initialize tracing: %v", err)
+	}
+	defer shutdownTracer(context.Background())
+
 	// Create a new Chi router
 	router := chi.NewRouter()
 
 	// Apply the logging middleware (Chi also has its own logging middleware if preferred)
 	router.Use(loggingMiddleware)
+	// Assigns/propagates a per-request trace ID, echoed into every problem+json body.
+	router.Use(apperrors.TraceIDMiddleware)
+	// Starts a per-request span (joining an inbound W3C traceparent, if any).
+	router.Use(appmiddleware.Tracing)
+	// Records request counts, in-flight gauges, and latency histograms for /metrics.
+	router.Use(appmiddleware.Metrics)
 	// router.Use(chi_middleware.Logger) // Alternative using Chi's logger
 
-	// Instantiate our Item API server implementation
+	// Instantiate our Item API server implementation, with the token-based
+	// auth subsystem enabled: every item is owned by whichever user created
+	// it, and AuthMiddleware (mounted below) is required to resolve a caller.
 	itemAPIServer := handlers.NewItemAPIServer(DB)
-
-	// Register the OpenAPI-generated handlers.
-	// The openapi.HandlerWithOptions function will register routes like /items/{id}
-	// onto the router passed to it, or create a new one.
-	// We can mount it on a sub-route e.g. /api/v1 or directly on root.
-	// For this example, let's assume the paths in openapi.yaml are root paths.
-	// openapi.Handler() creates a new chi router internally and mounts the generated handlers.
-	// We want to use our main router.
-
-	// Option 1: Let openapi.Handler create its own router and mount it
-	// itemAPIChiRouter := openapi.Handler(itemAPIServer)
-	// router.Mount("/items", itemAPIChiRouter) // This would make the path /items/items/{id} - likely not desired
-	// The paths in openapi.yaml are /items/{id}, so we want to use HandlerFromMux or HandlerWithOptions
-
-	// Option 2: Use HandlerFromMux to register generated routes on our main router
-	// This is generally cleaner if the generated paths are meant to be at the root of this router.
-	// The `openapi.HandlerWithOptions` function adds the routes to the provided BaseRouter.
-	// The generated `HandlerWithOptions` in `item_api.gen.go` looks like:
-	// r.Group(func(r chi.Router) {
-	//   r.Get(options.BaseURL+"/items/{id}", wrapper.GetItemById)
-	// })
-	// So, it will add "/items/{id}" to the router we pass.
-
-	// This will register GET /items/{id}, POST /items, and PUT /items/{id}
-	// (because UpdateItemById is now part of ServerInterface and implemented by ItemAPIServer)
-	openapi.HandlerWithOptions(itemAPIServer, openapi.ChiServerOptions{
-		BaseRouter: router, // Register on our main router
-		// Middlewares: []openapi.MiddlewareFunc{}, // Optional: API specific middlewares
-		// ErrorHandlerFunc: func(w http.ResponseWriter, r *http.Request, err error) { ... } // Optional
+	itemAPIServer.AuthDisabled = false
+
+	// Dispatcher fans item lifecycle events out to registered webhooks; wiring
+	// it onto itemAPIServer is what turns CreateItem/UpdateItemById/
+	// DeleteItemById into webhook triggers.
+	dispatcher := handlers.NewDispatcher(DB)
+	itemAPIServer.Dispatcher = dispatcher
+
+	webhookAPIServer := handlers.NewWebhookAPIServer(DB)
+	userAPIServer := handlers.NewUserAPIServer(DB)
+	authAPIServer := handlers.NewAuthAPIServer(DB)
+
+	// POST /users, /auth/register, and /auth/login are all unauthenticated
+	// (each is a way to obtain a bearer AuthMiddleware will accept), so
+	// they're registered on the bare router, before AuthMiddleware is
+	// applied to everything else below.
+	openapi.UserHandlerWithOptions(userAPIServer, openapi.UserChiServerOptions{
+		BaseRouter: router,
+	})
+	router.Post("/auth/register", authAPIServer.Register)
+	router.Post("/auth/login", authAPIServer.Login)
+
+	router.Group(func(r chi.Router) {
+		r.Use(handlers.AuthMiddleware(DB))
+
+		// Register the OpenAPI-generated handlers.
+		// The openapi.HandlerWithOptions function will register routes like /items/{id}
+		// onto the router passed to it, or create a new one.
+		// We can mount it on a sub-route e.g. /api/v1 or directly on root.
+		// For this example, let's assume the paths in openapi.yaml are root paths.
+		// openapi.Handler() creates a new chi router internally and mounts the generated handlers.
+		// We want to use our main router.
+
+		// Option 1: Let openapi.Handler create its own router and mount it
+		// itemAPIChiRouter := openapi.Handler(itemAPIServer)
+		// router.Mount("/items", itemAPIChiRouter) // This would make the path /items/items/{id} - likely not desired
+		// The paths in openapi.yaml are /items/{id}, so we want to use HandlerFromMux or HandlerWithOptions
+
+		// Option 2: Use HandlerFromMux to register generated routes on our main router
+		// This is generally cleaner if the generated paths are meant to be at the root of this router.
+		// The `openapi.HandlerWithOptions` function adds the routes to the provided BaseRouter.
+		// The generated `HandlerWithOptions` in `item_api.gen.go` looks like:
+		// r.Group(func(r chi.Router) {
+		//   r.Get(options.BaseURL+"/items/{id}", wrapper.GetItemById)
+		// })
+		// So, it will add "/items/{id}" to the router we pass.
+
+		// This will register GET /items/{id}, POST /items, and PUT /items/{id}
+		// (because UpdateItemById is now part of ServerInterface and implemented by ItemAPIServer)
+		openapi.HandlerWithOptions(itemAPIServer, openapi.ChiServerOptions{
+			BaseRouter: r, // Register within the authenticated group
+			// Middlewares: []openapi.MiddlewareFunc{}, // Optional: API specific middlewares
+			// ErrorHandlerFunc: func(w http.ResponseWriter, r *http.Request, err error) { ... } // Optional
+		})
 	})
 
-	// Register other existing API routes using Chi's syntax
-	// These handlers are from handlers/item_handlers.go
-	// Note: The GetItemHandler was removed, so we don't register it here.
-	// The POST /items route is now handled by the OpenAPI generated code via HandlerWithOptions.
-	router.Get("/items", handlers.GetItemsHandler(DB)) // For getting all items
-	// router.Put("/items/{id}", handlers.UpdateItemHandler(DB)) // THIS LINE IS REMOVED
-	// router.Delete("/items/{id}", handlers.DeleteItemHandler(DB)) // THIS LINE IS REMOVED
+	// Webhook subscriptions aren't user-owned, so they stay outside the
+	// authenticated group.
+	openapi.WebhookHandlerWithOptions(webhookAPIServer, openapi.WebhookChiServerOptions{
+		BaseRouter: router,
+	})
 
+	// GET/POST/PUT /items are handled by the authenticated, owner-filtered,
+	// paginated ItemAPIServer registered above via openapi.HandlerWithOptions.
+	router.Handle("/metrics", appmiddleware.Handler())
 
 	// Start the HTTP server
 	port := ":8080"
 	log.Printf("Server starting on port %s using Chi router", port) // Corrected log message formatting
-	if err := http.ListenAndServe(port, router); err != nil { // Pass the Chi router directly
+	if err := http.ListenAndServe(port, router); err != nil {       // Pass the Chi router directly
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsRecordsByRoutePattern(t *testing.T) {
+	router := chi.NewRouter()
+	router.Use(Metrics)
+	router.Get("/items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	router.Handle("/metrics", Handler())
+
+	req := httptest.NewRequest(http.MethodGet, "/items/42", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	metricsReq := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	metricsRR := httptest.NewRecorder()
+	router.ServeHTTP(metricsRR, metricsReq)
+	require.Equal(t, http.StatusOK, metricsRR.Code)
+
+	body := metricsRR.Body.String()
+	assert.Contains(t, body, `http_requests_total{method="GET",route="/items/{id}",status="200"}`,
+		"requests should be labeled by the templated route pattern, not the raw URL")
+	assert.NotContains(t, body, `route="/items/42"`, "the raw id must not become a label value")
+}
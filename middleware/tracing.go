@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies this package's spans in exported traces.
+const tracerName = "app/middleware"
+
+// InitTracer configures the global TracerProvider to export spans to an
+// OTLP endpoint. The endpoint and protocol are read from the standard
+// OTEL_EXPORTER_OTLP_ENDPOINT / OTEL_EXPORTER_OTLP_INSECURE env vars; when
+// OTEL_EXPORTER_OTLP_ENDPOINT is unset, tracing is left as a no-op so that
+// running the server without an observability stack nearby doesn't fail or
+// block on a connection. The returned shutdown func flushes and closes the
+// exporter and should be deferred from main.
+func InitTracer(serviceName string) (shutdown func(context.Context) error, err error) {
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	if endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(endpoint)}
+	if os.Getenv("OTEL_EXPORTER_OTLP_INSECURE") == "true" {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(context.Background(), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName(serviceName),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return tp.Shutdown, nil
+}
+
+// Tracing starts a span per request named "<method> <route pattern>",
+// extracting any inbound W3C traceparent header so this request's span
+// joins its caller's trace, and propagating the resulting context (which
+// carries the new span) down to the handler so database calls made during
+// the request can open child spans from it - see database.CreateItem et al.
+func Tracing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+		ctx, span := otel.Tracer(tracerName).Start(ctx, r.Method+" "+r.URL.Path,
+			trace.WithSpanKind(trace.SpanKindServer),
+		)
+		defer span.End()
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+
+		if route := routePattern(r); route != "unmatched" {
+			span.SetName(r.Method + " " + route)
+		}
+	})
+}
@@ -1,8 +1,15 @@
 package models
 
 type Item struct {
-	ID          int64   `json:"id"`
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Priority    int     `json:"priority"`
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Priority    int    `json:"priority"`
+	// Version is bumped on every successful update and backs the ETag /
+	// If-Match optimistic-concurrency checks in the item handlers.
+	Version int64 `json:"version"`
+	// OwnerUserID is the id of the user that created the item, stamped by
+	// ItemAPIServer.CreateItem from the authenticated request context. It
+	// is 0 for rows created while auth is disabled.
+	OwnerUserID int64 `json:"-"`
 }
@@ -0,0 +1,15 @@
+package models
+
+// User is an authenticated caller. It can be created two ways: CreateUser
+// issues one opaque bearer Token up front (the original, still-supported
+// POST /users flow); CreateUserWithCredentials instead stores a Username
+// and bcrypt PasswordHash, authenticated via POST /auth/login, which issues
+// a JWT rather than handing back a stored token. Items are stamped with
+// whichever user created them and authorized against by the item handlers,
+// regardless of which scheme authenticated the caller.
+type User struct {
+	ID           int64
+	Token        string
+	Username     string
+	PasswordHash string
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// WebhookSubscription is a registered outbound webhook: events matching
+// Events are POSTed to URL, signed with Secret (see handlers.Dispatcher).
+type WebhookSubscription struct {
+	ID        int64     `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret"`
+	CreatedAt time.Time `json:"created_at"`
+}